@@ -0,0 +1,315 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"amartha/internal/model"
+)
+
+// Distribusi amount yang didukung.
+const (
+	distUniform   = "uniform"
+	distLognormal = "lognormal"
+)
+
+// Kategori baris ground truth. fee_outside dan lag adalah pasangan riil yang
+// tidak dapat dijangkau engine (tanggal sama, dalam toleransi), sehingga
+// dicatat sebagai dua record tanpa pasangan dengan kolom counterpart.
+const (
+	truthExact       = "exact"        // amount dan tanggal sama
+	truthFee         = "fee"          // selisih fee dalam toleransi
+	truthFeeOutside  = "fee_outside"  // selisih fee di atas toleransi
+	truthLag         = "lag"          // bank membukukan setelah tanggal transaksi
+	truthDuplicate   = "duplicate"    // baris bank duplikat tanpa pasangan sistem
+	truthMissingBank = "missing_bank" // transaksi sistem tanpa baris bank
+	truthBankOnly    = "bank_only"    // baris bank tanpa transaksi sistem
+)
+
+// config mengatur volume dan jenis noise data sintetis. Rate adalah peluang
+// per transaksi (0..1).
+type config struct {
+	Start, End        time.Time
+	Banks             []string
+	PerDay            int
+	Seed              int64
+	AmountDist        string
+	AmountMin         int64
+	AmountMax         int64
+	DebitRate         float64
+	Tolerance         int64
+	FeeRate           float64
+	FeeOutsideRate    float64
+	LagRate           float64
+	MaxLag            int
+	DuplicateRate     float64
+	MissingBankRate   float64
+	MissingSystemRate float64
+}
+
+func defaultConfig() config {
+	return config{
+		Banks:             []string{"bankA", "bankB"},
+		PerDay:            100,
+		Seed:              1,
+		AmountDist:        distLognormal,
+		AmountMin:         10000,
+		AmountMax:         5000000,
+		DebitRate:         0.3,
+		Tolerance:         5000,
+		FeeRate:           0.05,
+		FeeOutsideRate:    0.01,
+		LagRate:           0.02,
+		MaxLag:            2,
+		DuplicateRate:     0.01,
+		MissingBankRate:   0.02,
+		MissingSystemRate: 0.02,
+	}
+}
+
+func (c config) validate() error {
+	if c.End.Before(c.Start) {
+		return fmt.Errorf("end date must be on or after start date")
+	}
+	if len(c.Banks) == 0 {
+		return fmt.Errorf("at least one bank is required")
+	}
+	seen := map[string]bool{}
+	for _, b := range c.Banks {
+		if seen[b] {
+			return fmt.Errorf("duplicate bank %q", b)
+		}
+		seen[b] = true
+	}
+	if c.PerDay < 0 {
+		return fmt.Errorf("per-day must not be negative")
+	}
+	switch c.AmountDist {
+	case distUniform, distLognormal:
+	default:
+		return fmt.Errorf("unknown amount distribution %q", c.AmountDist)
+	}
+	if c.AmountMin <= 0 || c.AmountMax < c.AmountMin {
+		return fmt.Errorf("amount range must satisfy 0 < min <= max")
+	}
+	if c.Tolerance < 0 {
+		return fmt.Errorf("tolerance must not be negative")
+	}
+	if c.MaxLag < 0 || (c.LagRate > 0 && c.MaxLag < 1) {
+		return fmt.Errorf("max-lag must be at least 1 when lag-rate is positive")
+	}
+	rates := map[string]float64{
+		"debit-rate": c.DebitRate, "fee-rate": c.FeeRate, "fee-outside-rate": c.FeeOutsideRate,
+		"lag-rate": c.LagRate, "duplicate-rate": c.DuplicateRate,
+		"missing-bank-rate": c.MissingBankRate, "missing-system-rate": c.MissingSystemRate,
+	}
+	for name, r := range rates {
+		if r < 0 || r > 1 {
+			return fmt.Errorf("%s must be between 0 and 1", name)
+		}
+	}
+	if c.FeeRate+c.FeeOutsideRate+c.LagRate+c.MissingBankRate > 1 {
+		return fmt.Errorf("fee-rate + fee-outside-rate + lag-rate + missing-bank-rate must not exceed 1")
+	}
+	return nil
+}
+
+// truthRow adalah satu baris ground truth. TrxID atau BankID kosong berarti
+// record tersebut seharusnya tidak memiliki pasangan. Counterpart (hanya
+// fee_outside dan lag) menunjuk pasangan riil record tersebut: "bank/ID" untuk
+// record sistem, trxID untuk record bank.
+type truthRow struct {
+	TrxID       string
+	BankName    string
+	BankID      string
+	Category    string
+	Counterpart string
+}
+
+type bankRow struct {
+	ID     string
+	Amount int64
+	Date   time.Time
+}
+
+// dataset adalah hasil generator.
+type dataset struct {
+	System []model.SystemTransaction
+	Banks  map[string][]bankRow
+	Truth  []truthRow
+	order  []string // urutan nama bank untuk output deterministik
+}
+
+func (d dataset) bankCount() int {
+	n := 0
+	for _, rows := range d.Banks {
+		n += len(rows)
+	}
+	return n
+}
+
+// generate membangkitkan dataset secara deterministik dari cfg.Seed.
+func generate(cfg config) dataset {
+	r := rand.New(rand.NewSource(cfg.Seed))
+	ds := dataset{Banks: map[string][]bankRow{}, order: cfg.Banks}
+	seq := map[string]int{}
+	nextBankID := func(bank string) string {
+		seq[bank]++
+		return fmt.Sprintf("%s-%07d", bank, seq[bank])
+	}
+
+	trx := 0
+	for day := cfg.Start; !day.After(cfg.End); day = day.AddDate(0, 0, 1) {
+		for i := 0; i < cfg.PerDay; i++ {
+			trx++
+			amt := cfg.amount(r)
+			typ, signed := "CREDIT", amt
+			if r.Float64() < cfg.DebitRate {
+				typ, signed = "DEBIT", -amt
+			}
+			tx := model.SystemTransaction{
+				TrxID:           fmt.Sprintf("TRX-%08d", trx),
+				Amount:          amt,
+				Type:            typ,
+				TransactionTime: day.Add(time.Duration(r.Intn(24*3600)) * time.Second),
+			}
+			ds.System = append(ds.System, tx)
+
+			bank := cfg.Banks[r.Intn(len(cfg.Banks))]
+			row := bankRow{Amount: signed, Date: day}
+			category := truthExact
+			switch p := r.Float64(); {
+			case p < cfg.MissingBankRate:
+				ds.Truth = append(ds.Truth, truthRow{TrxID: tx.TrxID, Category: truthMissingBank})
+				continue
+			case p < cfg.MissingBankRate+cfg.FeeRate:
+				if fee, ok := cfg.feeBelow(r, 1, cfg.Tolerance, amt); ok {
+					category, row.Amount = truthFee, signed-fee
+				}
+			case p < cfg.MissingBankRate+cfg.FeeRate+cfg.FeeOutsideRate:
+				if fee, ok := cfg.feeBelow(r, cfg.Tolerance+1, 4*cfg.Tolerance+1000, amt); ok {
+					category, row.Amount = truthFeeOutside, signed-fee
+				}
+			case p < cfg.MissingBankRate+cfg.FeeRate+cfg.FeeOutsideRate+cfg.LagRate:
+				category = truthLag
+				row.Date = day.AddDate(0, 0, 1+r.Intn(cfg.MaxLag))
+			}
+			row.ID = nextBankID(bank)
+			ds.Banks[bank] = append(ds.Banks[bank], row)
+			if category == truthFeeOutside || category == truthLag {
+				ds.Truth = append(ds.Truth,
+					truthRow{TrxID: tx.TrxID, Category: category, Counterpart: bank + "/" + row.ID},
+					truthRow{BankName: bank, BankID: row.ID, Category: category, Counterpart: tx.TrxID})
+			} else {
+				ds.Truth = append(ds.Truth, truthRow{TrxID: tx.TrxID, BankName: bank, BankID: row.ID, Category: category})
+			}
+
+			if r.Float64() < cfg.DuplicateRate {
+				dup := row
+				dup.ID = nextBankID(bank)
+				ds.Banks[bank] = append(ds.Banks[bank], dup)
+				ds.Truth = append(ds.Truth, truthRow{BankName: bank, BankID: dup.ID, Category: truthDuplicate})
+			}
+			if r.Float64() < cfg.MissingSystemRate {
+				other := cfg.Banks[r.Intn(len(cfg.Banks))]
+				extra := bankRow{ID: nextBankID(other), Amount: -cfg.fee(r, 1000, 50000), Date: day}
+				ds.Banks[other] = append(ds.Banks[other], extra)
+				ds.Truth = append(ds.Truth, truthRow{BankName: other, BankID: extra.ID, Category: truthBankOnly})
+			}
+		}
+	}
+	for _, rows := range ds.Banks {
+		sort.SliceStable(rows, func(i, j int) bool { return rows[i].Date.Before(rows[j].Date) })
+	}
+	return ds
+}
+
+// amount menghasilkan amount positif sesuai distribusi, dibulatkan ke 100.
+func (c config) amount(r *rand.Rand) int64 {
+	var v float64
+	switch c.AmountDist {
+	case distLognormal:
+		// Median di rata-rata geometris min dan max; ~95% nilai di dalam rentang.
+		lo, hi := math.Log(float64(c.AmountMin)), math.Log(float64(c.AmountMax))
+		v = math.Exp((lo+hi)/2 + r.NormFloat64()*(hi-lo)/4)
+	default:
+		v = float64(c.AmountMin) + r.Float64()*float64(c.AmountMax-c.AmountMin)
+	}
+	amt := int64(math.Round(v/100)) * 100
+	if amt < c.AmountMin {
+		amt = c.AmountMin
+	}
+	if amt > c.AmountMax {
+		amt = c.AmountMax
+	}
+	return amt
+}
+
+// fee menghasilkan fee acak di [lo, hi].
+func (c config) fee(r *rand.Rand, lo, hi int64) int64 {
+	if hi <= lo {
+		return lo
+	}
+	return lo + r.Int63n(hi-lo+1)
+}
+
+// feeBelow seperti fee, tetapi dibatasi di bawah amt agar tanda baris bank
+// sama dengan transaksi sistemnya; ok false bila tidak ada fee yang muat
+// (baris bank kemudian dibuat exact).
+func (c config) feeBelow(r *rand.Rand, lo, hi, amt int64) (int64, bool) {
+	if hi > amt-1 {
+		hi = amt - 1
+	}
+	if lo > hi {
+		return 0, false
+	}
+	return c.fee(r, lo, hi), true
+}
+
+// write menulis system_transactions.csv, <bank>.csv, dan ground_truth.csv ke dir.
+func (d dataset) write(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	sys := [][]string{{"trxID", "amount", "type", "transactionTime"}}
+	for _, tx := range d.System {
+		sys = append(sys, []string{tx.TrxID, strconv.FormatInt(tx.Amount, 10), tx.Type, tx.TransactionTime.Format(time.RFC3339)})
+	}
+	if err := writeCSV(filepath.Join(dir, "system_transactions.csv"), sys); err != nil {
+		return err
+	}
+	for _, bank := range d.order {
+		rows := [][]string{{"unique_identifier", "amount", "date"}}
+		for _, b := range d.Banks[bank] {
+			rows = append(rows, []string{b.ID, strconv.FormatInt(b.Amount, 10), b.Date.Format("2006-01-02")})
+		}
+		if err := writeCSV(filepath.Join(dir, bank+".csv"), rows); err != nil {
+			return err
+		}
+	}
+	truth := [][]string{{"trxID", "bank_name", "unique_identifier", "category", "counterpart"}}
+	for _, t := range d.Truth {
+		truth = append(truth, []string{t.TrxID, t.BankName, t.BankID, t.Category, t.Counterpart})
+	}
+	return writeCSV(filepath.Join(dir, "ground_truth.csv"), truth)
+}
+
+func writeCSV(path string, rows [][]string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if err := w.WriteAll(rows); err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return f.Close()
+}
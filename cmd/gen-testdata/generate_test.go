@@ -0,0 +1,229 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"amartha/internal/loader"
+)
+
+func testConfig() config {
+	cfg := defaultConfig()
+	cfg.Start = time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	cfg.End = time.Date(2025, 6, 5, 0, 0, 0, 0, time.UTC)
+	cfg.Banks = []string{"bankA", "bankB", "bankC"}
+	cfg.PerDay = 200
+	cfg.FeeRate, cfg.FeeOutsideRate, cfg.LagRate = 0.1, 0.05, 0.05
+	cfg.DuplicateRate, cfg.MissingBankRate, cfg.MissingSystemRate = 0.05, 0.05, 0.05
+	return cfg
+}
+
+func TestGenerate_Deterministic(t *testing.T) {
+	cfg := testConfig()
+	a, b := generate(cfg), generate(cfg)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed produced different datasets")
+	}
+	cfg.Seed++
+	if reflect.DeepEqual(a, generate(cfg)) {
+		t.Fatalf("different seeds produced identical datasets")
+	}
+}
+
+func TestGenerate_GroundTruth(t *testing.T) {
+	cfg := testConfig()
+	ds := generate(cfg)
+	if len(ds.System) != 5*cfg.PerDay {
+		t.Fatalf("expected %d system transactions, got %d", 5*cfg.PerDay, len(ds.System))
+	}
+
+	sys := map[string]int64{}
+	sysDate := map[string]time.Time{}
+	for _, tx := range ds.System {
+		signed := tx.Amount
+		if tx.Type == "DEBIT" {
+			signed = -signed
+		}
+		if tx.Amount < cfg.AmountMin || tx.Amount > cfg.AmountMax {
+			t.Fatalf("amount out of range: %+v", tx)
+		}
+		sys[tx.TrxID] = signed
+		sysDate[tx.TrxID] = tx.TransactionTime.Truncate(24 * time.Hour)
+	}
+	bank := map[string]bankRow{}
+	for name, rows := range ds.Banks {
+		for _, r := range rows {
+			bank[name+"/"+r.ID] = r
+		}
+	}
+
+	// Setiap transaksi sistem dan baris bank muncul tepat sekali di ground truth.
+	seenSys, seenBank := map[string]int{}, map[string]int{}
+	categories := map[string]int{}
+	for _, tr := range ds.Truth {
+		categories[tr.Category]++
+		if tr.TrxID != "" {
+			seenSys[tr.TrxID]++
+		}
+		if tr.BankID != "" {
+			seenBank[tr.BankName+"/"+tr.BankID]++
+		}
+		// Pasangan di luar jangkauan engine hanya dirujuk lewat counterpart.
+		bankKey := tr.BankName + "/" + tr.BankID
+		switch {
+		case tr.Category == truthFeeOutside || tr.Category == truthLag:
+			if (tr.TrxID == "") == (tr.BankID == "") || tr.Counterpart == "" {
+				t.Fatalf("%s row must be a single unpaired record with a counterpart: %+v", tr.Category, tr)
+			}
+			if tr.TrxID == "" {
+				continue
+			}
+			bankKey = tr.Counterpart
+		case tr.TrxID == "" || tr.BankID == "":
+			continue
+		}
+		b, ok := bank[bankKey]
+		if !ok {
+			t.Fatalf("unknown bank row for %+v", tr)
+		}
+		if (sys[tr.TrxID] < 0) != (b.Amount < 0) || b.Amount == 0 {
+			t.Fatalf("%s bank row flips sign: system %d, bank %d", tr.Category, sys[tr.TrxID], b.Amount)
+		}
+		diff := sys[tr.TrxID] - b.Amount
+		if diff < 0 {
+			diff = -diff
+		}
+		sameDay := b.Date.Equal(sysDate[tr.TrxID])
+		switch tr.Category {
+		case truthExact:
+			if diff != 0 || !sameDay {
+				t.Fatalf("exact row differs: %+v", tr)
+			}
+		case truthFee:
+			if diff == 0 || diff > cfg.Tolerance || !sameDay {
+				t.Fatalf("fee row outside tolerance: %+v diff %d", tr, diff)
+			}
+		case truthFeeOutside:
+			if diff <= cfg.Tolerance || !sameDay {
+				t.Fatalf("fee_outside row within tolerance: %+v diff %d", tr, diff)
+			}
+		case truthLag:
+			if diff != 0 || !b.Date.After(sysDate[tr.TrxID]) || b.Date.After(sysDate[tr.TrxID].AddDate(0, 0, cfg.MaxLag)) {
+				t.Fatalf("unexpected lag row: %+v", tr)
+			}
+		default:
+			t.Fatalf("unexpected category for paired row: %+v", tr)
+		}
+	}
+	for id := range sys {
+		if seenSys[id] != 1 {
+			t.Fatalf("system %s appears %d times in ground truth", id, seenSys[id])
+		}
+	}
+	for id := range bank {
+		if seenBank[id] != 1 {
+			t.Fatalf("bank row %s appears %d times in ground truth", id, seenBank[id])
+		}
+	}
+	for _, c := range []string{truthExact, truthFee, truthFeeOutside, truthLag, truthDuplicate, truthMissingBank, truthBankOnly} {
+		if categories[c] == 0 {
+			t.Fatalf("expected some %s rows, got %v", c, categories)
+		}
+	}
+}
+
+func TestGenerate_FeeKeepsSign(t *testing.T) {
+	cfg := testConfig()
+	// Fee di atas toleransi (hingga 4*toleransi+1000) bisa melebihi amount kecil.
+	cfg.AmountMin, cfg.AmountMax = 1000, 30000
+	cfg.FeeRate, cfg.FeeOutsideRate = 0.3, 0.5
+	ds := generate(cfg)
+
+	sys := map[string]int64{}
+	for _, tx := range ds.System {
+		sys[tx.TrxID] = tx.Amount
+		if tx.Type == "DEBIT" {
+			sys[tx.TrxID] = -tx.Amount
+		}
+	}
+	bank := map[string]int64{}
+	for name, rows := range ds.Banks {
+		for _, r := range rows {
+			bank[name+"/"+r.ID] = r.Amount
+		}
+	}
+	var checked int
+	for _, tr := range ds.Truth {
+		key := tr.BankName + "/" + tr.BankID
+		switch {
+		case tr.Category == truthFeeOutside && tr.TrxID != "":
+			key = tr.Counterpart
+		case tr.Category != truthFee:
+			continue
+		}
+		s, b := sys[tr.TrxID], bank[key]
+		if b == 0 || (s < 0) != (b < 0) {
+			t.Fatalf("%s bank row %s flips sign: system %d, bank %d", tr.Category, key, s, b)
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Fatalf("expected fee rows to check")
+	}
+}
+
+func TestGenerate_WriteLoadable(t *testing.T) {
+	cfg := testConfig()
+	cfg.PerDay = 20
+	dir := t.TempDir()
+	ds := generate(cfg)
+	if err := ds.write(dir); err != nil {
+		t.Fatalf("write error: %v", err)
+	}
+	txs, err := loader.LoadSystemCSV(filepath.Join(dir, "system_transactions.csv"))
+	if err != nil || len(txs) != len(ds.System) {
+		t.Fatalf("LoadSystemCSV: %d rows, %v", len(txs), err)
+	}
+	for _, b := range cfg.Banks {
+		rows, err := loader.LoadBankCSV(filepath.Join(dir, b+".csv"), b)
+		if err != nil || len(rows) != len(ds.Banks[b]) {
+			t.Fatalf("LoadBankCSV %s: %d rows, %v", b, len(rows), err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ground_truth.csv")); err != nil {
+		t.Fatalf("missing ground truth: %v", err)
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	cfg, out, err := parseArgs([]string{"--start", "2025-06-01", "--end", "2025-06-02", "--banks", "bankA, bankB", "--out", "x", "--amount-dist", "uniform"})
+	if err != nil {
+		t.Fatalf("parseArgs error: %v", err)
+	}
+	if out != "x" || len(cfg.Banks) != 2 || cfg.Banks[1] != "bankB" || cfg.AmountDist != distUniform {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	// Tanpa lag, max-lag tidak dipakai dan boleh 0.
+	if _, _, err := parseArgs([]string{"--start", "2025-06-01", "--end", "2025-06-02", "--lag-rate", "0", "--max-lag", "0"}); err != nil {
+		t.Fatalf("parseArgs error for zero lag: %v", err)
+	}
+
+	bad := [][]string{
+		{"--end", "2025-06-02"},
+		{"--start", "2025-06-03", "--end", "2025-06-02"},
+		{"--start", "2025-06-01", "--end", "2025-06-02", "--banks", "bankA,bankA"},
+		{"--start", "2025-06-01", "--end", "2025-06-02", "--amount-dist", "pareto"},
+		{"--start", "2025-06-01", "--end", "2025-06-02", "--fee-rate", "1.5"},
+		{"--start", "2025-06-01", "--end", "2025-06-02", "--amount-min", "0"},
+		{"--start", "2025-06-01", "--end", "2025-06-02", "--max-lag", "0"},
+	}
+	for _, args := range bad {
+		if _, _, err := parseArgs(args); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+}
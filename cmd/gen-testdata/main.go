@@ -0,0 +1,102 @@
+// Command gen-testdata membangkitkan data sintetis untuk menguji rekonsiliasi
+// dalam skala besar: CSV transaksi sistem, CSV per bank, dan ground truth
+// pasangan yang seharusnya matched.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+func main() {
+	cfg, outDir, err := parseArgs(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("invalid arguments: %v", err)
+	}
+	ds := generate(cfg)
+	if err := ds.write(outDir); err != nil {
+		log.Fatalf("failed to write data: %v", err)
+	}
+	log.Printf("wrote %d system transactions, %d bank statements and %d ground truth rows to %s",
+		len(ds.System), ds.bankCount(), len(ds.Truth), outDir)
+}
+
+// parseArgs membaca flag menjadi config generator dan direktori output.
+func parseArgs(args []string) (config, string, error) {
+	def := defaultConfig()
+	fs := flag.NewFlagSet("gen-testdata", flag.ContinueOnError)
+	outDir := fs.String("out", "gen", "Output directory")
+	startStr := fs.String("start", "", "Start date YYYY-MM-DD (required)")
+	endStr := fs.String("end", "", "End date YYYY-MM-DD (required, inclusive)")
+	banks := fs.String("banks", strings.Join(def.Banks, ","), "Comma-separated bank names")
+	perDay := fs.Int("per-day", def.PerDay, "System transactions per day")
+	seed := fs.Int64("seed", def.Seed, "Random seed; the same seed produces identical files")
+	dist := fs.String("amount-dist", def.AmountDist, "Amount distribution: uniform or lognormal")
+	minAmt := fs.Int64("amount-min", def.AmountMin, "Minimum amount")
+	maxAmt := fs.Int64("amount-max", def.AmountMax, "Maximum amount")
+	debitRate := fs.Float64("debit-rate", def.DebitRate, "Fraction of DEBIT transactions")
+	tolerance := fs.Int64("tolerance", def.Tolerance, "Matching tolerance used to size fee noise")
+	feeRate := fs.Float64("fee-rate", def.FeeRate, "Fraction of bank rows with a fee within tolerance")
+	feeOutsideRate := fs.Float64("fee-outside-rate", def.FeeOutsideRate, "Fraction of bank rows with a fee above tolerance")
+	lagRate := fs.Float64("lag-rate", def.LagRate, "Fraction of bank rows settled after the transaction date")
+	maxLag := fs.Int("max-lag", def.MaxLag, "Maximum settlement lag in days")
+	dupRate := fs.Float64("duplicate-rate", def.DuplicateRate, "Fraction of bank rows duplicated in the statement")
+	missingBankRate := fs.Float64("missing-bank-rate", def.MissingBankRate, "Fraction of system transactions without a bank row")
+	missingSystemRate := fs.Float64("missing-system-rate", def.MissingSystemRate, "Extra bank-only rows as a fraction of system transactions")
+	if err := fs.Parse(args); err != nil {
+		return config{}, "", err
+	}
+	if *startStr == "" || *endStr == "" {
+		return config{}, "", fmt.Errorf("--start and --end are required")
+	}
+	start, err := time.Parse("2006-01-02", *startStr)
+	if err != nil {
+		return config{}, "", fmt.Errorf("invalid --start: %w", err)
+	}
+	end, err := time.Parse("2006-01-02", *endStr)
+	if err != nil {
+		return config{}, "", fmt.Errorf("invalid --end: %w", err)
+	}
+
+	cfg := config{
+		Start:             start,
+		End:               end,
+		Banks:             splitNames(*banks),
+		PerDay:            *perDay,
+		Seed:              *seed,
+		AmountDist:        *dist,
+		AmountMin:         *minAmt,
+		AmountMax:         *maxAmt,
+		DebitRate:         *debitRate,
+		Tolerance:         *tolerance,
+		FeeRate:           *feeRate,
+		FeeOutsideRate:    *feeOutsideRate,
+		LagRate:           *lagRate,
+		MaxLag:            *maxLag,
+		DuplicateRate:     *dupRate,
+		MissingBankRate:   *missingBankRate,
+		MissingSystemRate: *missingSystemRate,
+	}
+	if err := cfg.validate(); err != nil {
+		return config{}, "", err
+	}
+	return cfg, *outDir, nil
+}
+
+func splitNames(s string) []string {
+	var out []string
+	for _, n := range strings.Split(s, ",") {
+		if n = strings.TrimSpace(n); n != "" {
+			out = append(out, n)
+		}
+	}
+	return out
+}
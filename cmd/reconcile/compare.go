@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+
+	"amartha/internal/diff"
+)
+
+// runCompare menjalankan `reconcile compare`: membandingkan dua file hasil
+// tersimpan (mis. output kemarin dan rerun hari ini) dan menulis laporan
+// perbedaan dalam format yang sama dengan `reconcile diff`.
+func runCompare(args []string) error {
+	fs := flag.NewFlagSet("reconcile compare", flag.ContinueOnError)
+	beforePath := fs.String("before", "", "Path to the earlier result JSON")
+	afterPath := fs.String("after", "", "Path to the later result JSON")
+	output := fs.String("output", "", "Write JSON report to path (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *beforePath == "" || *afterPath == "" {
+		return fmt.Errorf("--before and --after are required")
+	}
+	before, err := readResult(*beforePath)
+	if err != nil {
+		return err
+	}
+	after, err := readResult(*afterPath)
+	if err != nil {
+		return err
+	}
+	return writeOutputs(diff.Results(before, after), []outputConfig{{Path: *output, Format: outputFormatJSON}})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"amartha/internal/diff"
+	"amartha/internal/model"
+)
+
+func TestRunCompare(t *testing.T) {
+	dir := t.TempDir()
+	before, err := readResult(filepath.Join("testdata", "golden", "result.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rerun setelah file bankA dikoreksi: BA-05 hilang, dan transaksi sistem
+	// TRX-2009 yang baru masuk belum punya pasangan.
+	after := before
+	after.Details.UnmatchedSystem = []model.NormalizedRecord{{ID: "TRX-2009", Amount: 30000}}
+	after.Details.UnmatchedBankByGroup = map[string][]model.NormalizedRecord{
+		"bankB": before.Details.UnmatchedBankByGroup["bankB"],
+	}
+	afterPath := filepath.Join(dir, "after.json")
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, after); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(afterPath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := filepath.Join(dir, "compare.json")
+	if err := runCompare([]string{"--before", filepath.Join("testdata", "golden", "result.json"), "--after", afterPath, "--output", out}); err != nil {
+		t.Fatalf("runCompare error: %v", err)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep diff.Report
+	if err := json.Unmarshal(raw, &rep); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if len(rep.Added)+len(rep.Removed)+len(rep.Repaired) != 0 || rep.Unchanged != len(before.Details.Matched) {
+		t.Fatalf("unexpected pair changes: %+v", rep)
+	}
+	if len(rep.NewlyCleared.System) != 0 || len(rep.NewlyCleared.Bank) != 1 || len(rep.NewlyCleared.Bank["bankA"]) != 1 ||
+		rep.NewlyCleared.Bank["bankA"][0].ID != "BA-05" {
+		t.Fatalf("unexpected newly cleared: %+v", rep.NewlyCleared)
+	}
+	if len(rep.NewlyUnmatched.System) != 1 || rep.NewlyUnmatched.System[0].ID != "TRX-2009" || len(rep.NewlyUnmatched.Bank) != 0 {
+		t.Fatalf("unexpected newly unmatched: %+v", rep.NewlyUnmatched)
+	}
+
+	for _, args := range [][]string{
+		{"--before", afterPath},
+		{"--before", afterPath, "--after", filepath.Join(dir, "missing.json")},
+	} {
+		if err := runCompare(args); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"amartha/internal/loader"
+	"amartha/internal/reconcile"
+)
+
+// jobConfig adalah isi file konfigurasi job rekonsiliasi (YAML atau JSON).
+type jobConfig struct {
+	System    systemConfig      `json:"system"`
+	Banks     []bankConfig      `json:"banks"`
+	BankFiles []bankFilesConfig `json:"bank_files"`
+	DateRange dateRangeConfig   `json:"date_range"`
+	Timezone  string            `json:"timezone"`
+	Matching  matchingConfig    `json:"matching"`
+	Outputs   []outputConfig    `json:"outputs"`
+}
+
+type systemConfig struct {
+	Path    string               `json:"path"`
+	Profile loader.SystemProfile `json:"profile"`
+}
+
+// bankConfig mendeklarasikan satu bank; semua file pada Paths digabungkan.
+// Paths boleh kosong jika file bank ditemukan lewat bank_files; profile
+// tetap berlaku untuk file yang ditemukan dengan nama bank yang sama.
+type bankConfig struct {
+	Name    string             `json:"name"`
+	Paths   []string           `json:"paths"`
+	Format  string             `json:"format"` // csv, camt053, atau mt940; kosong = dari ekstensi
+	Profile loader.BankProfile `json:"profile"`
+}
+
+// bankFilesConfig mencari file bank di direktori (Dir) atau lewat pola glob
+// (Glob); nama bank diambil dari nama file memakai Pattern, mis. "{bank}_{date}.csv".
+// EntryPattern (opsional) menamai bank per entri CSV di dalam zip yang ditemukan.
+type bankFilesConfig struct {
+	Dir          string `json:"dir"`
+	Glob         string `json:"glob"`
+	Pattern      string `json:"pattern"`
+	EntryPattern string `json:"entry_pattern"`
+}
+
+// glob mengembalikan pola glob untuk pencarian file.
+func (b bankFilesConfig) glob() string {
+	if b.Glob != "" {
+		return b.Glob
+	}
+	return filepath.Join(b.Dir, "*")
+}
+
+// dateRangeConfig menerima tanggal absolut (YYYY-MM-DD) atau relatif
+// ("today", "yesterday"), atau preset Period (lihat resolvePeriod);
+// keduanya di-resolve pada zona waktu job.
+type dateRangeConfig struct {
+	Start  string `json:"start"`
+	End    string `json:"end"`
+	Period string `json:"period"`
+}
+
+type matchingConfig struct {
+	Strategy              string              `json:"strategy"`
+	Tolerance             *int64              `json:"tolerance"`
+	BankTolerances        map[string]int64    `json:"bank_tolerances"`
+	BalanceReconciliation bool                `json:"balance_reconciliation"`
+	DescriptionTieBreak   bool                `json:"description_tiebreak"`
+	FeeRules              []reconcile.FeeRule `json:"fee_rules"`
+	Reversals             reversalConfig      `json:"reversals"`
+	SignAgnostic          bool                `json:"sign_agnostic"`
+	// FailOnBalanceBreak membuat job gagal (exit non-zero) bila ada balance
+	// break, karena statement yang tidak konsisten membatalkan hasil matching.
+	FailOnBalanceBreak bool `json:"fail_on_balance_break"`
+}
+
+// reversalConfig mengatur pre-pass pembatalan (lihat reconcile.Options.Reversals).
+type reversalConfig struct {
+	Enabled       bool `json:"enabled"`
+	WindowDays    int  `json:"window_days"`
+	AllowUnlinked bool `json:"allow_unlinked"`
+}
+
+// outputConfig menentukan tujuan hasil; path kosong atau "-" berarti stdout.
+type outputConfig struct {
+	Path   string `json:"path"`
+	Format string `json:"format"`
+}
+
+const outputFormatJSON = "json"
+
+// loadConfig membaca file konfigurasi; format ditentukan dari ekstensi
+// (.yaml/.yml untuk YAML, selain itu JSON). Key yang tidak dikenal ditolak.
+// Path relatif di dalam konfigurasi di-resolve terhadap direktori file konfigurasi.
+func loadConfig(path string) (jobConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return jobConfig{}, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if raw, err = yamlToJSON(raw); err != nil {
+			return jobConfig{}, fmt.Errorf("parse config %s: %w", path, err)
+		}
+	}
+	cfg, err := decodeConfig(raw)
+	if err != nil {
+		return jobConfig{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	cfg.resolvePaths(filepath.Dir(path))
+	return cfg, nil
+}
+
+// decodeConfig mendekode JSON secara ketat: key tidak dikenal dan data sisa ditolak.
+func decodeConfig(raw []byte) (jobConfig, error) {
+	var cfg jobConfig
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return jobConfig{}, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return jobConfig{}, fmt.Errorf("unexpected data after config object")
+	}
+	return cfg, nil
+}
+
+// yamlToJSON mengubah dokumen YAML menjadi JSON agar validasi ketat
+// dan tag struct cukup didefinisikan sekali (json). Tanggal tanpa kutip
+// (mis. 2025-06-01) dipertahankan sebagai string.
+func yamlToJSON(raw []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	var v interface{} = map[string]interface{}{}
+	if len(doc.Content) > 0 {
+		timestampsAsStrings(&doc)
+		if err := doc.Decode(&v); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(v)
+}
+
+func timestampsAsStrings(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.ShortTag() == "!!timestamp" {
+		n.Tag = "!!str"
+	}
+	for _, c := range n.Content {
+		timestampsAsStrings(c)
+	}
+}
+
+func (c *jobConfig) resolvePaths(dir string) {
+	if c.System.Path != "-" {
+		c.System.Path = resolvePath(dir, c.System.Path)
+	}
+	for i := range c.Banks {
+		for j, p := range c.Banks[i].Paths {
+			c.Banks[i].Paths[j] = resolvePath(dir, p)
+		}
+	}
+	for i := range c.BankFiles {
+		c.BankFiles[i].Dir = resolvePath(dir, c.BankFiles[i].Dir)
+		c.BankFiles[i].Glob = resolvePath(dir, c.BankFiles[i].Glob)
+	}
+	for i, o := range c.Outputs {
+		if o.Path != "-" {
+			c.Outputs[i].Path = resolvePath(dir, o.Path)
+		}
+	}
+}
+
+func resolvePath(dir, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(dir, p)
+}
+
+// validate memeriksa nilai konfigurasi yang sudah digabung dengan flag.
+func (c jobConfig) validate() error {
+	if c.System.Path == "" {
+		return fmt.Errorf("system path is required")
+	}
+	if err := c.System.Profile.Validate(); err != nil {
+		return fmt.Errorf("system profile: %w", err)
+	}
+	if len(c.Banks) == 0 && len(c.BankFiles) == 0 {
+		return fmt.Errorf("at least one bank or bank_files entry is required")
+	}
+	seen := map[string]bool{}
+	for i, b := range c.Banks {
+		if b.Name == "" {
+			return fmt.Errorf("banks[%d]: name is required", i)
+		}
+		if seen[b.Name] {
+			return fmt.Errorf("banks[%d]: duplicate bank name %q", i, b.Name)
+		}
+		seen[b.Name] = true
+		if len(b.Paths) == 0 && len(c.BankFiles) == 0 {
+			return fmt.Errorf("banks[%d]: at least one path is required", i)
+		}
+		switch b.Format {
+		case "", loader.FormatCSV, loader.FormatCamt053, loader.FormatMT940:
+		default:
+			return fmt.Errorf("banks[%d]: unknown format %q", i, b.Format)
+		}
+		if err := b.Profile.Validate(); err != nil {
+			return fmt.Errorf("banks[%d] profile: %w", i, err)
+		}
+	}
+	for i, bf := range c.BankFiles {
+		if (bf.Dir == "") == (bf.Glob == "") {
+			return fmt.Errorf("bank_files[%d]: exactly one of dir or glob is required", i)
+		}
+		if _, err := loader.CompileNamePattern(bf.Pattern); err != nil {
+			return fmt.Errorf("bank_files[%d]: %w", i, err)
+		}
+		if bf.EntryPattern != "" {
+			if _, err := loader.CompileNamePattern(bf.EntryPattern); err != nil {
+				return fmt.Errorf("bank_files[%d] entry_pattern: %w", i, err)
+			}
+		}
+	}
+	if c.DateRange.Period != "" {
+		if c.DateRange.Start != "" || c.DateRange.End != "" {
+			return fmt.Errorf("date_range period cannot be combined with start or end")
+		}
+	} else if c.DateRange.Start == "" || c.DateRange.End == "" {
+		return fmt.Errorf("date_range start and end (or period) are required")
+	}
+	for i, o := range c.Outputs {
+		switch o.Format {
+		case "", outputFormatJSON:
+		default:
+			return fmt.Errorf("outputs[%d]: unknown format %q", i, o.Format)
+		}
+	}
+	return nil
+}
+
+// resolveDate mengubah ekspresi tanggal absolut atau relatif menjadi tanggal
+// (00:00 UTC) sesuai kalender pada zona loc saat now.
+func resolveDate(expr string, now time.Time, loc *time.Location) (time.Time, error) {
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	switch strings.ToLower(strings.TrimSpace(expr)) {
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+	d, err := time.Parse("2006-01-02", expr)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q: expected YYYY-MM-DD, today or yesterday", expr)
+	}
+	return d, nil
+}
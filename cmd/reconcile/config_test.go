@@ -0,0 +1,249 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"amartha/internal/reconcile"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return p
+}
+
+func TestLoadConfig_YAML(t *testing.T) {
+	p := writeConfig(t, "job.yaml", `
+system:
+  path: sys.csv
+  profile:
+    id_column: trx_id
+banks:
+  - name: bankA
+    paths: [a1.csv, /abs/a2.csv]
+    profile:
+      date_layout: 02/01/2006
+date_range:
+  start: 2025-06-01
+  end: yesterday
+timezone: Asia/Jakarta
+matching:
+  strategy: amount
+  tolerance: 1000
+  bank_tolerances:
+    bankA: 2000
+  fee_rules:
+    - {bank: bankA, fixed: [2500, 6500]}
+    - {mdr_percent: 0.7}
+outputs:
+  - path: out.json
+    format: json
+`)
+	cfg, err := loadConfig(p)
+	if err != nil {
+		t.Fatalf("loadConfig error: %v", err)
+	}
+	dir := filepath.Dir(p)
+	if cfg.System.Path != filepath.Join(dir, "sys.csv") || cfg.System.Profile.IDColumn != "trx_id" {
+		t.Fatalf("unexpected system config: %+v", cfg.System)
+	}
+	if len(cfg.Banks) != 1 || cfg.Banks[0].Paths[0] != filepath.Join(dir, "a1.csv") || cfg.Banks[0].Paths[1] != "/abs/a2.csv" {
+		t.Fatalf("unexpected banks: %+v", cfg.Banks)
+	}
+	if cfg.Banks[0].Profile.DateLayout != "02/01/2006" {
+		t.Fatalf("unexpected bank profile: %+v", cfg.Banks[0].Profile)
+	}
+	if cfg.DateRange.Start != "2025-06-01" || cfg.DateRange.End != "yesterday" {
+		t.Fatalf("unexpected date range: %+v", cfg.DateRange)
+	}
+	if cfg.Matching.Strategy != "amount" || cfg.Matching.Tolerance == nil || *cfg.Matching.Tolerance != 1000 || cfg.Matching.BankTolerances["bankA"] != 2000 {
+		t.Fatalf("unexpected matching: %+v", cfg.Matching)
+	}
+	if len(cfg.Matching.FeeRules) != 2 || cfg.Matching.FeeRules[0].Fixed[1] != 6500 || cfg.Matching.FeeRules[1].MDRPercent != 0.7 {
+		t.Fatalf("unexpected fee rules: %+v", cfg.Matching.FeeRules)
+	}
+	if cfg.Outputs[0].Path != filepath.Join(dir, "out.json") {
+		t.Fatalf("unexpected outputs: %+v", cfg.Outputs)
+	}
+}
+
+func TestLoadConfig_UnknownKey(t *testing.T) {
+	yml := writeConfig(t, "job.yml", "system:\n  path: sys.csv\n  delimiter: ';'\n")
+	if _, err := loadConfig(yml); err == nil || !strings.Contains(err.Error(), "delimiter") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+	js := writeConfig(t, "job.json", `{"matching": {"tolerence": 10}}`)
+	if _, err := loadConfig(js); err == nil || !strings.Contains(err.Error(), "tolerence") {
+		t.Fatalf("expected unknown key error, got %v", err)
+	}
+}
+
+func TestParseJob_FlagsOverrideConfig(t *testing.T) {
+	p := writeConfig(t, "job.json", `{
+  "system": {"path": "sys.csv", "profile": {"types": {"TOPUP": "CREDIT"}}},
+  "banks": [{"name": "bankA", "paths": ["a.csv"], "profile": {"amount_column": "nominal"}}],
+  "date_range": {"start": "2025-06-01", "end": "2025-06-03"},
+  "matching": {"tolerance": 1000, "reversals": {"window_days": 2, "allow_unlinked": true}}
+}`)
+	now := time.Date(2025, 6, 10, 20, 0, 0, 0, time.UTC)
+	j, err := parseJob([]string{
+		"--config", p,
+		"--bank", "bankA=override.csv",
+		"--end", "yesterday",
+		"--tolerance", "0",
+		"--tz", "Asia/Jakarta",
+		"--balance-recon",
+		"--reversals",
+		"--sign-agnostic",
+		"--fail-on-balance-break",
+		"--strategy", "amount",
+		"--type", "REVERSAL=DEBIT",
+		"--unknown-types", "report",
+	}, now)
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if j.SystemPath != filepath.Join(filepath.Dir(p), "sys.csv") {
+		t.Fatalf("unexpected system path: %s", j.SystemPath)
+	}
+	if len(j.Banks) != 1 || j.Banks[0].Path != "override.csv" || j.Banks[0].Profile.AmountColumn != "nominal" {
+		t.Fatalf("unexpected banks: %+v", j.Banks)
+	}
+	// 20:00 UTC adalah 03:00 WIB keesokan harinya, sehingga yesterday = 2025-06-10.
+	if !j.Start.Equal(mustDate(t, "2025-06-01")) || !j.End.Equal(mustDate(t, "2025-06-10")) {
+		t.Fatalf("unexpected range: %s - %s", j.Start, j.End)
+	}
+	if prof := j.SystemProfile; prof.Types["TOPUP"] != "CREDIT" || prof.Types["REVERSAL"] != "DEBIT" || prof.UnknownTypes != "report" {
+		t.Fatalf("expected config and flag types merged, got %+v", prof)
+	}
+	if j.Options.Strategy != reconcile.StrategyAmount {
+		t.Fatalf("expected strategy amount, got %q", j.Options.Strategy)
+	}
+	if j.Options.Tolerance != 0 {
+		t.Fatalf("expected tolerance override 0, got %d", j.Options.Tolerance)
+	}
+	if !j.Options.BalanceReconciliation {
+		t.Fatalf("expected balance reconciliation enabled")
+	}
+	if !j.Options.Reversals || j.Options.ReversalWindow != 2 || !j.Options.ReversalAllowUnlinked {
+		t.Fatalf("expected reversals enabled with config window and allow_unlinked, got %+v", j.Options)
+	}
+	if !j.Options.SignAgnostic {
+		t.Fatalf("expected sign-agnostic mode enabled")
+	}
+	if !j.FailOnBalanceBreak {
+		t.Fatalf("expected fail-on-balance-break enabled")
+	}
+	if len(j.Outputs) != 1 || j.Outputs[0].Path != "-" {
+		t.Fatalf("expected default stdout output, got %+v", j.Outputs)
+	}
+}
+
+func TestParseJob_BankOverrideMultiBankConfig(t *testing.T) {
+	p := writeConfig(t, "job.json", `{
+  "system": {"path": "sys.csv"},
+  "banks": [
+    {"name": "bankA", "paths": ["a.csv"], "profile": {"amount_column": "nominal"}},
+    {"name": "bankB", "paths": ["b.csv"]}
+  ],
+  "date_range": {"start": "2025-06-01", "end": "2025-06-03"}
+}`)
+	j, err := parseJob([]string{"--config", p, "--bank", "bankA=x.csv"}, time.Now())
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	// Hanya bank dari flag yang dimuat, dengan profile dari konfigurasi.
+	if len(j.Banks) != 1 || j.Banks[0].Name != "bankA" || j.Banks[0].Path != "x.csv" || j.Banks[0].Profile.AmountColumn != "nominal" {
+		t.Fatalf("unexpected banks: %+v", j.Banks)
+	}
+}
+
+func TestParseJob_Invalid(t *testing.T) {
+	now := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	cases := [][]string{
+		{"--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02"},
+		{"--system", "s.csv", "--start", "2025-06-01", "--end", "2025-06-02"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-03", "--end", "2025-06-02"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "06/01/2025", "--end", "2025-06-02"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--strategy", "magic"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--tz", "Mars/Base"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--reversal-window", "-1"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--type", "REVERSAL"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--type", "REVERSAL=REFUND"},
+		{"--system", "s.csv", "--bank", "a.csv", "--start", "2025-06-01", "--end", "2025-06-02", "--unknown-types", "ignore"},
+	}
+	for _, args := range cases {
+		if _, err := parseJob(args, now); err == nil {
+			t.Fatalf("expected error for %v", args)
+		}
+	}
+
+	p := writeConfig(t, "job.json", `{
+  "system": {"path": "sys.csv"},
+  "banks": [{"name": "bankA", "paths": ["a.csv"]}],
+  "date_range": {"start": "2025-06-01", "end": "2025-06-02"},
+  "matching": {"strategy": "magic"}
+}`)
+	if _, err := parseJob([]string{"--config", p}, now); err == nil || !strings.Contains(err.Error(), "magic") {
+		t.Fatalf("expected unknown strategy error, got %v", err)
+	}
+}
+
+func mustDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		t.Fatalf("parse date: %v", err)
+	}
+	return d
+}
+
+func TestParseJob_BankDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"bankA_2025-06-01.csv": "unique_identifier,amount,date\nBA-1,100,2025-06-01\n",
+		"bankA_2025-06-02.csv": "unique_identifier,amount,date\nBA-2,200,2025-06-02\n",
+		"bankB_2025-06-01.csv": "ref;nominal\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	p := writeConfig(t, "job.json", `{
+  "system": {"path": "s.csv"},
+  "banks": [{"name": "bankB", "profile": {"id_column": "ref"}}],
+  "bank_files": [{"dir": "`+dir+`", "pattern": "{bank}_{date}.csv"}],
+  "date_range": {"period": "yesterday"}
+}`)
+	j, err := parseJob([]string{"--config", p}, time.Now())
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if len(j.Banks) != 3 {
+		t.Fatalf("expected 3 bank files, got %+v", j.Banks)
+	}
+	for _, b := range j.Banks {
+		if b.Name == "bankB" && b.Profile.IDColumn != "ref" {
+			t.Fatalf("expected bankB profile from config, got %+v", b)
+		}
+	}
+
+	j, err = parseJob([]string{"--config", p, "--bank-glob", filepath.Join(dir, "bankA_*.csv"), "--bank-pattern", "{bank}_{date}.csv"}, time.Now())
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if len(j.Banks) != 2 || j.Banks[0].Name != "bankA" {
+		t.Fatalf("expected 2 bankA files from glob, got %+v", j.Banks)
+	}
+
+	if _, err := parseJob([]string{"--system", "s.csv", "--bank-dir", dir, "--bank-pattern", "{bank}-x.csv", "--date", "today"}, time.Now()); err == nil {
+		t.Fatalf("expected error when no files match the pattern")
+	}
+}
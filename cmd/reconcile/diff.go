@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"amartha/internal/diff"
+	"amartha/internal/reconcile"
+)
+
+// runDiff menjalankan `reconcile diff [flag job] -- [flag kandidat]`:
+// rekonsiliasi dijalankan dua kali atas input yang sama, sekali dengan flag
+// job (baseline) dan sekali dengan flag job ditambah flag kandidat (mis.
+// --tolerance 10000), lalu perbedaan pasangan dan ringkasan ditulis ke output
+// job.
+func runDiff(args []string, now time.Time) error {
+	base, over := splitArgs(args)
+	if len(over) == 0 {
+		return fmt.Errorf("candidate flags are required after --, e.g. diff --config job.yaml -- --tolerance 10000")
+	}
+	baseline, err := parseJob(base, now)
+	if err != nil {
+		return fmt.Errorf("baseline: %w", err)
+	}
+	candidate, err := parseJob(append(append([]string{}, base...), over...), now)
+	if err != nil {
+		return fmt.Errorf("candidate: %w", err)
+	}
+	if !sameInputs(baseline, candidate) {
+		return fmt.Errorf("candidate flags must only change matching options, not inputs or date range")
+	}
+	if !reflect.DeepEqual(baseline.Outputs, candidate.Outputs) {
+		return fmt.Errorf("output flags belong before --; the candidate side only changes matching options")
+	}
+
+	sysTxs := mustLoadSystem(systemSource(baseline.SystemPath, baseline.SystemProfile, os.Stdin))
+	bankData, balances := mustLoadBanks(baseline.Banks)
+	baseline.Options.Balances = balances
+	candidate.Options.Balances = balances
+	before, err := reconcile.ReconcileWithOptions(sysTxs, bankData, baseline.Start, baseline.End, baseline.Options)
+	if err != nil {
+		return fmt.Errorf("baseline reconciliation: %w", err)
+	}
+	after, err := reconcile.ReconcileWithOptions(sysTxs, bankData, candidate.Start, candidate.End, candidate.Options)
+	if err != nil {
+		return fmt.Errorf("candidate reconciliation: %w", err)
+	}
+	return writeOutputs(diff.Results(before, after), baseline.Outputs)
+}
+
+// splitArgs memisahkan argumen pada "--" pertama.
+func splitArgs(args []string) (before, after []string) {
+	for i, a := range args {
+		if a == "--" {
+			return args[:i], args[i+1:]
+		}
+	}
+	return args, nil
+}
+
+// sameInputs melaporkan apakah kedua job membaca data dan rentang tanggal yang sama.
+func sameInputs(a, b job) bool {
+	return a.SystemPath == b.SystemPath &&
+		reflect.DeepEqual(a.SystemProfile, b.SystemProfile) &&
+		reflect.DeepEqual(a.Banks, b.Banks) &&
+		a.Start.Equal(b.Start) && a.End.Equal(b.End)
+}
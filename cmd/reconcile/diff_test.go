@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"amartha/internal/diff"
+)
+
+func TestRunDiff(t *testing.T) {
+	dir := filepath.Join("testdata", "golden")
+	out := filepath.Join(t.TempDir(), "diff.json")
+	job := []string{
+		"--system", filepath.Join(dir, "system.csv"),
+		"--bank", "bankA=" + filepath.Join(dir, "bankA.csv"),
+		"--bank", "bankB=" + filepath.Join(dir, "bankB.csv"),
+		"--start", "2025-06-01",
+		"--end", "2025-06-02",
+		"--tolerance", "0",
+		"--output", out,
+	}
+	if err := runDiff(append(job, "--", "--tolerance", "5000"), time.Now()); err != nil {
+		t.Fatalf("runDiff error: %v", err)
+	}
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rep diff.Report
+	if err := json.Unmarshal(raw, &rep); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	// TRX-2008 (20000) baru cocok dengan BB-05 (21000) pada toleransi 5000.
+	if len(rep.Added) != 1 || rep.Added[0].SystemID != "TRX-2008" || rep.Added[0].BankID != "BB-05" ||
+		len(rep.Removed) != 0 || len(rep.Repaired) != 0 {
+		t.Fatalf("unexpected pair changes: %+v", rep)
+	}
+	if rep.Summary.Delta.TotalMatched != 1 || rep.Summary.Delta.TotalDiscrepancies != 1000 {
+		t.Fatalf("unexpected summary delta: %+v", rep.Summary.Delta)
+	}
+
+	for name, args := range map[string][]string{
+		"no candidate":  job,
+		"changes input": append(job[:len(job):len(job)], "--", "--end", "2025-06-01"),
+		"bad candidate": append(job[:len(job):len(job)], "--", "--tolerance", "-1"),
+		"candidate out": append(job[:len(job):len(job)], "--", "--tolerance", "5000", "--output", "-"),
+	} {
+		if err := runDiff(args, time.Now()); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"amartha/internal/eval"
+	"amartha/internal/model"
+)
+
+// evalReport adalah keluaran subcommand eval: satu Report per hasil
+// (strategi) yang dinilai terhadap ground truth yang sama.
+type evalReport struct {
+	Truth      string        `json:"truth"`
+	TruthPairs int           `json:"truth_pairs"`
+	Results    []eval.Report `json:"results"`
+}
+
+// runEval menjalankan `reconcile eval`: menilai satu atau lebih file hasil
+// (JSON model.Result) terhadap file ground truth dan menulis laporan JSON ke w.
+func runEval(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("reconcile eval", flag.ContinueOnError)
+	truthPath := fs.String("truth", "", "Path to ground truth CSV (trxID,bank_name,unique_identifier[,category])")
+	var results multiFlag
+	fs.Var(&results, "result", "Result JSON to score as path or label=path, e.g. tiebreak=out.json (repeatable; label defaults to file name)")
+	details := fs.Bool("details", false, "List false positive and missed pairs in the report")
+	output := fs.String("output", "", "Write JSON report to path (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+	if *truthPath == "" {
+		return fmt.Errorf("--truth is required")
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("at least one --result is required")
+	}
+
+	truth, err := eval.LoadGroundTruth(*truthPath)
+	if err != nil {
+		return err
+	}
+	rep := evalReport{Truth: *truthPath, TruthPairs: len(truth)}
+	seen := map[string]bool{}
+	for _, v := range results {
+		// Label dan path memakai bentuk yang sama dengan --bank.
+		f, err := parseBankFlag(v)
+		if err != nil {
+			return fmt.Errorf("--result %q: %w", v, err)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("--result %q: duplicate label %q", v, f.Name)
+		}
+		seen[f.Name] = true
+		res, err := readResult(f.Path)
+		if err != nil {
+			return err
+		}
+		rep.Results = append(rep.Results, eval.Score(f.Name, res, truth, *details))
+	}
+
+	if *output == "" || *output == "-" {
+		return writeJSON(w, rep)
+	}
+	out, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := writeJSON(out, rep); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readResult membaca file hasil rekonsiliasi (keluaran --output).
+func readResult(path string) (model.Result, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return model.Result{}, err
+	}
+	var res model.Result
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return model.Result{}, fmt.Errorf("parse result %s: %w", path, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunEval(t *testing.T) {
+	dir := t.TempDir()
+	truth := filepath.Join(dir, "ground_truth.csv")
+	if err := os.WriteFile(truth, []byte("trxID,bank_name,unique_identifier,category\n"+
+		"TRX-2001,bankA,BA-01,exact\nTRX-2002,bankA,BA-02,exact\nTRX-9999,bankA,BA-99,lag\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := runEval([]string{"--truth", truth, "--result", "golden=testdata/golden/result.json", "--details"}, &out); err != nil {
+		t.Fatalf("runEval error: %v", err)
+	}
+	var rep evalReport
+	if err := json.Unmarshal(out.Bytes(), &rep); err != nil {
+		t.Fatalf("decode report: %v\n%s", err, out.String())
+	}
+	if rep.TruthPairs != 3 || len(rep.Results) != 1 {
+		t.Fatalf("unexpected report: %+v", rep)
+	}
+	r := rep.Results[0]
+	if r.Label != "golden" || r.TruePositives != 2 || r.Missed != 1 || r.FalsePositives != 6 {
+		t.Fatalf("unexpected score: %+v", r)
+	}
+
+	bad := [][]string{
+		{"--result", "testdata/golden/result.json"},
+		{"--truth", truth},
+		{"--truth", truth, "--result", "a=testdata/golden/result.json", "--result", "a=testdata/golden/result.json"},
+		{"--truth", truth, "--result", "testdata/golden/system.csv"},
+		{"--truth", truth, "--result", "testdata/golden/result.json", "extra"},
+	}
+	for _, args := range bad {
+		if err := runEval(args, &out); err == nil {
+			t.Fatalf("%v: expected error", args)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"amartha/internal/reconcile"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestGolden_DeterministicOutput memastikan input yang penuh amount kembar
+// menghasilkan JSON yang identik byte demi byte di setiap run.
+func TestGolden_DeterministicOutput(t *testing.T) {
+	dir := filepath.Join("testdata", "golden")
+	j, err := parseJob([]string{
+		"--system", filepath.Join(dir, "system.csv"),
+		"--bank", "bankB=" + filepath.Join(dir, "bankB.csv"),
+		"--bank", "bankA=" + filepath.Join(dir, "bankA.csv"),
+		"--start", "2025-06-01",
+		"--end", "2025-06-02",
+		"--balance-recon",
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+
+	run := func() []byte {
+		sys := mustLoadSystem(systemSource(j.SystemPath, j.SystemProfile, nil))
+		banks, bals := mustLoadBanks(j.Banks)
+		opts := j.Options
+		opts.Balances = bals
+		res, err := reconcile.ReconcileWithOptions(sys, banks, j.Start, j.End, opts)
+		if err != nil {
+			t.Fatalf("ReconcileWithOptions error: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := writeJSON(&buf, res); err != nil {
+			t.Fatalf("writeJSON error: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	got := run()
+	golden := filepath.Join(dir, "result.json")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("write golden: %v", err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("read golden: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("output differs from %s (run with -update to regenerate):\n%s", golden, got)
+	}
+	for i := 0; i < 20; i++ {
+		if again := run(); !bytes.Equal(again, want) {
+			t.Fatalf("run %d produced different output:\n%s", i+2, again)
+		}
+	}
+}
@@ -2,12 +2,16 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
+	_ "time/tzdata"
 
 	"amartha/internal/loader"
 	"amartha/internal/model"
@@ -15,68 +19,476 @@ import (
 )
 
 func main() {
-	sysPath, bankPaths, start, end := parseArgs()
-	sysTxs := mustLoadSystemCSV(sysPath)
-	bankData := mustLoadBanks(bankPaths)
-	res, err := reconcile.Reconcile(sysTxs, bankData, start, end)
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			err := run(os.Args[2:])
+			if errors.Is(err, flag.ErrHelp) {
+				os.Exit(0)
+			}
+			if err != nil {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+	j, err := parseJob(os.Args[1:], time.Now())
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		log.Fatalf("invalid arguments: %v", err)
+	}
+	sysTxs := mustLoadSystem(systemSource(j.SystemPath, j.SystemProfile, os.Stdin))
+	bankData, balances := mustLoadBanks(j.Banks)
+	j.Options.Balances = balances
+	res, err := reconcile.ReconcileWithOptions(sysTxs, bankData, j.Start, j.End, j.Options)
 	if err != nil {
 		log.Fatalf("reconciliation error: %v", err)
 	}
-	enc := json.NewEncoder(os.Stdout)
-	enc.SetIndent("", "  ")
-	if err := enc.Encode(res); err != nil {
-		log.Fatalf("failed to encode result: %v", err)
+	if err := writeOutputs(res, j.Outputs); err != nil {
+		log.Fatalf("failed to write result: %v", err)
+	}
+	for _, b := range res.Details.BalanceBreaks {
+		log.Printf("warning: balance break for bank %s on %s (source %q, statement %q): opening %d + total %d != closing %d (difference %d)",
+			b.BankName, b.Date, b.Source, b.Statement, b.Opening, b.Total, b.Closing, b.Difference)
+	}
+	if err := checkBalanceBreaks(res, j.FailOnBalanceBreak); err != nil {
+		log.Fatalf("reconciliation invalid: %v", err)
 	}
+	if n := res.Summary.TotalUnknownTypes; n > 0 {
+		log.Printf("warning: %d system transactions with an unknown type were excluded from matching (see details.unknown_types)", n)
+	}
+	for _, inv := range res.Details.SignInversions {
+		name := inv.BankName
+		if inv.Source != "" {
+			name += " (" + inv.Source + ")"
+		}
+		log.Printf("warning: bank %s looks sign-inverted: %d of %d records (%.0f%%) only match system amounts with the opposite sign; consider --sign-agnostic",
+			name, inv.Inverted, inv.Compared, inv.Ratio*100)
+	}
+}
+
+// subcommands adalah perintah tambahan selain rekonsiliasi biasa
+// (`reconcile <nama> ...`).
+var subcommands = map[string]func(args []string) error{
+	"eval":    func(args []string) error { return runEval(args, os.Stdout) },
+	"diff":    func(args []string) error { return runDiff(args, time.Now()) },
+	"compare": runCompare,
+}
+
+// job adalah parameter rekonsiliasi final setelah konfigurasi dan flag digabung.
+type job struct {
+	SystemPath    string
+	SystemProfile loader.SystemProfile
+	Banks         []loader.BankFile
+	Start, End    time.Time
+	Options       reconcile.Options
+	Outputs       []outputConfig
+	// FailOnBalanceBreak: balance break menggagalkan job setelah output ditulis.
+	FailOnBalanceBreak bool
 }
 
-func parseArgs() (string, []string, time.Time, time.Time) {
-	systemPath := flag.String("system", "", "Path to system transactions CSV (required)")
+// parseJob membaca flag dan (opsional) file konfigurasi. Flag yang diberikan
+// secara eksplisit menimpa nilai dari konfigurasi.
+func parseJob(args []string, now time.Time) (job, error) {
+	fs := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	configPath := fs.String("config", "", "Path to job config file (.yaml, .yml or .json)")
+	systemPath := fs.String("system", "", "Path to system transactions CSV, - for stdin")
+	var types multiFlag
+	fs.Var(&types, "type", "Extra system transaction type as VALUE=CREDIT or VALUE=DEBIT, e.g. REVERSAL=DEBIT (repeatable)")
+	unknownTypes := fs.String("unknown-types", "", "Handling of system types outside the vocabulary: reject (default) or report")
 	var bankPaths multiFlag
-	flag.Var(&bankPaths, "bank", "Path to bank statement CSV (repeatable)")
-	startStr := flag.String("start", "", "Start date YYYY-MM-DD (inclusive)")
-	endStr := flag.String("end", "", "End date YYYY-MM-DD (inclusive)")
-	flag.Parse()
-	if *systemPath == "" || len(bankPaths) == 0 || *startStr == "" || *endStr == "" {
-		flag.Usage()
-		os.Exit(2)
-	}
-	start, err := time.Parse("2006-01-02", *startStr)
-	if err != nil {
-		log.Fatalf("invalid start date: %v", err)
+	fs.Var(&bankPaths, "bank", "Bank statement file (CSV, camt.053 .xml or MT940 .sta) as path or name=path (repeatable; files with the same name are merged)")
+	var bankDirs, bankGlobs multiFlag
+	fs.Var(&bankDirs, "bank-dir", "Directory of bank statement files (repeatable)")
+	fs.Var(&bankGlobs, "bank-glob", "Glob pattern of bank statement files, e.g. 'in/*.csv' (repeatable)")
+	bankPattern := fs.String("bank-pattern", "", "File name to bank name pattern for --bank-dir/--bank-glob, e.g. '{bank}_{date}.csv' (default {bank}.*)")
+	bankEntryPattern := fs.String("bank-entry-pattern", "", "Name pattern for CSV entries inside zip files found by --bank-dir/--bank-glob, e.g. '{bank}.csv'; entries then load as separate banks")
+	startStr := fs.String("start", "", "Start date YYYY-MM-DD, today or yesterday (inclusive)")
+	endStr := fs.String("end", "", "End date YYYY-MM-DD, today or yesterday (inclusive)")
+	period := fs.String("period", "", "Date range preset: today, yesterday, last-<N>d, month-to-date or last-month")
+	date := fs.String("date", "", "Single date YYYY-MM-DD, today or yesterday (shorthand for --start X --end X)")
+	tolerance := fs.Int64("tolerance", 0, "Maximum amount discrepancy for a match (default 5000)")
+	strategy := fs.String("strategy", "", "Matching strategy (default amount)")
+	balanceRecon := fs.Bool("balance-recon", false, "Add a per bank and date balance reconciliation section to the result")
+	descTieBreak := fs.Bool("description-tiebreak", false, "Prefer the most similar description among same-amount candidates")
+	reversals := fs.Bool("reversals", false, "Detect offsetting reversal pairs within each source and exclude them from matching")
+	reversalWindow := fs.Int("reversal-window", 0, "Maximum days between a record and its reversal (default 0, same day)")
+	signAgnostic := fs.Bool("sign-agnostic", false, "Also pair leftover records by absolute amount and report them as sign mismatches")
+	failOnBreak := fs.Bool("fail-on-balance-break", false, "Exit non-zero after writing the result when a bank statement balance break is found")
+	tz := fs.String("tz", "", "Reconciliation timezone, e.g. Asia/Jakarta (default UTC)")
+	var outputs multiFlag
+	fs.Var(&outputs, "output", "Write JSON result to path, - for stdout (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return job{}, err
+	}
+
+	var cfg jobConfig
+	if *configPath != "" {
+		var err error
+		if cfg, err = loadConfig(*configPath); err != nil {
+			return job{}, err
+		}
+	}
+
+	// Bank di konfigurasi tetap menjadi sumber format dan profile kolom
+	// walaupun inputnya diganti flag.
+	declared := declaredBanks(cfg.Banks)
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["system"] {
+		cfg.System.Path = *systemPath
+	}
+	if set["type"] {
+		// Type dari flag ditambahkan ke kosakata profile sistem.
+		merged := map[string]string{}
+		for k, v := range cfg.System.Profile.Types {
+			merged[k] = v
+		}
+		for _, t := range types {
+			k, v, ok := strings.Cut(t, "=")
+			if !ok || strings.TrimSpace(k) == "" {
+				return job{}, fmt.Errorf("invalid --type %q, want VALUE=CREDIT or VALUE=DEBIT", t)
+			}
+			merged[k] = v
+		}
+		cfg.System.Profile.Types = merged
+	}
+	if set["unknown-types"] {
+		cfg.System.Profile.UnknownTypes = *unknownTypes
+	}
+	if set["bank"] || set["bank-dir"] || set["bank-glob"] {
+		// Input bank dari flag menggantikan seluruh input bank di konfigurasi.
+		banks, err := banksFromFlags(bankPaths, declared)
+		if err != nil {
+			return job{}, err
+		}
+		cfg.Banks = banks
+		cfg.BankFiles = nil
+		for _, d := range bankDirs {
+			cfg.BankFiles = append(cfg.BankFiles, bankFilesConfig{Dir: d, Pattern: *bankPattern, EntryPattern: *bankEntryPattern})
+		}
+		for _, g := range bankGlobs {
+			cfg.BankFiles = append(cfg.BankFiles, bankFilesConfig{Glob: g, Pattern: *bankPattern, EntryPattern: *bankEntryPattern})
+		}
+	} else {
+		for i := range cfg.BankFiles {
+			if set["bank-pattern"] {
+				cfg.BankFiles[i].Pattern = *bankPattern
+			}
+			if set["bank-entry-pattern"] {
+				cfg.BankFiles[i].EntryPattern = *bankEntryPattern
+			}
+		}
+	}
+	if n := countSet(set, "start", "end", "period", "date"); n > 0 {
+		if (set["period"] || set["date"]) && n > 1 {
+			return job{}, fmt.Errorf("--period and --date cannot be combined with other date flags")
+		}
+		// Flag tanggal apa pun menggantikan rentang tanggal dari konfigurasi.
+		if set["period"] || set["date"] {
+			cfg.DateRange = dateRangeConfig{Period: *period, Start: *date, End: *date}
+		} else {
+			cfg.DateRange.Period = ""
+			if set["start"] {
+				cfg.DateRange.Start = *startStr
+			}
+			if set["end"] {
+				cfg.DateRange.End = *endStr
+			}
+		}
+	}
+	if set["tolerance"] {
+		cfg.Matching.Tolerance = tolerance
+	}
+	if set["strategy"] {
+		cfg.Matching.Strategy = *strategy
+	}
+	if set["balance-recon"] {
+		cfg.Matching.BalanceReconciliation = *balanceRecon
+	}
+	if set["description-tiebreak"] {
+		cfg.Matching.DescriptionTieBreak = *descTieBreak
+	}
+	if set["reversals"] {
+		cfg.Matching.Reversals.Enabled = *reversals
+	}
+	if set["reversal-window"] {
+		cfg.Matching.Reversals.WindowDays = *reversalWindow
+	}
+	if set["sign-agnostic"] {
+		cfg.Matching.SignAgnostic = *signAgnostic
+	}
+	if set["fail-on-balance-break"] {
+		cfg.Matching.FailOnBalanceBreak = *failOnBreak
+	}
+	if set["tz"] {
+		cfg.Timezone = *tz
+	}
+	if set["output"] {
+		cfg.Outputs = nil
+		for _, p := range outputs {
+			cfg.Outputs = append(cfg.Outputs, outputConfig{Path: p, Format: outputFormatJSON})
+		}
 	}
-	end, err := time.Parse("2006-01-02", *endStr)
+	if err := cfg.validate(); err != nil {
+		return job{}, err
+	}
+	return buildJob(cfg, declared, now)
+}
+
+// buildJob mengubah konfigurasi tervalidasi menjadi job siap jalan. declared
+// adalah bank yang dideklarasikan di konfigurasi (lihat collectBankFiles).
+func buildJob(cfg jobConfig, declared map[string]bankConfig, now time.Time) (job, error) {
+	opts := reconcile.DefaultOptions()
+	if cfg.Timezone != "" {
+		loc, err := time.LoadLocation(cfg.Timezone)
+		if err != nil {
+			return job{}, fmt.Errorf("invalid timezone %q: %w", cfg.Timezone, err)
+		}
+		opts.Location = loc
+	}
+	if cfg.Matching.Strategy != "" {
+		opts.Strategy = cfg.Matching.Strategy
+	}
+	if cfg.Matching.Tolerance != nil {
+		opts.Tolerance = *cfg.Matching.Tolerance
+	}
+	opts.BankTolerances = cfg.Matching.BankTolerances
+	opts.BalanceReconciliation = cfg.Matching.BalanceReconciliation
+	opts.DescriptionTieBreak = cfg.Matching.DescriptionTieBreak
+	opts.FeeRules = cfg.Matching.FeeRules
+	opts.Reversals = cfg.Matching.Reversals.Enabled
+	opts.ReversalWindow = cfg.Matching.Reversals.WindowDays
+	opts.ReversalAllowUnlinked = cfg.Matching.Reversals.AllowUnlinked
+	opts.SignAgnostic = cfg.Matching.SignAgnostic
+	if err := opts.Validate(); err != nil {
+		return job{}, err
+	}
+
+	start, end, err := resolveDateRange(cfg.DateRange, now, opts.Location)
 	if err != nil {
-		log.Fatalf("invalid end date: %v", err)
+		return job{}, err
 	}
 	if end.Before(start) {
-		log.Fatalf("end date must be on or after start date")
+		return job{}, fmt.Errorf("end date must be on or after start date")
+	}
+
+	j := job{
+		SystemPath:         cfg.System.Path,
+		SystemProfile:      cfg.System.Profile,
+		Start:              start,
+		End:                end,
+		Options:            opts,
+		Outputs:            cfg.Outputs,
+		FailOnBalanceBreak: cfg.Matching.FailOnBalanceBreak,
+	}
+	banks, err := collectBankFiles(cfg, declared)
+	if err != nil {
+		return job{}, err
+	}
+	j.Banks = banks
+	if len(j.Outputs) == 0 {
+		j.Outputs = []outputConfig{{Path: "-", Format: outputFormatJSON}}
 	}
-	return *systemPath, []string(bankPaths), start, end
+	return j, nil
 }
 
-func mustLoadSystemCSV(p string) []model.SystemTransaction {
-	txs, err := loader.LoadSystemCSV(p)
+// resolveDateRange menghasilkan tanggal awal dan akhir dari preset periode
+// atau dari pasangan start/end.
+func resolveDateRange(r dateRangeConfig, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	if r.Period != "" {
+		return resolvePeriod(r.Period, now, loc)
+	}
+	start, err := resolveDate(r.Start, now, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := resolveDate(r.End, now, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid end date: %w", err)
+	}
+	return start, end, nil
+}
+
+func countSet(set map[string]bool, names ...string) int {
+	n := 0
+	for _, name := range names {
+		if set[name] {
+			n++
+		}
+	}
+	return n
+}
+
+// collectBankFiles menggabungkan file bank yang dideklarasikan eksplisit dengan
+// file yang ditemukan lewat bank_files. Format dan profile kolom diambil dari
+// bank dengan nama yang sama.
+func collectBankFiles(cfg jobConfig, declared map[string]bankConfig) ([]loader.BankFile, error) {
+	profiles := map[string]loader.BankProfile{}
+	for name, b := range declared {
+		profiles[name] = b.Profile
+	}
+	var out []loader.BankFile
+	for _, b := range cfg.Banks {
+		for _, p := range b.Paths {
+			out = append(out, loader.BankFile{Name: b.Name, Path: p, Format: b.Format, Profile: b.Profile})
+		}
+	}
+	for _, bf := range cfg.BankFiles {
+		pattern, err := loader.CompileNamePattern(bf.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		found, err := loader.FindBankFiles(bf.glob(), pattern)
+		if err != nil {
+			return nil, err
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("no bank files in %s match pattern %s", bf.glob(), pattern)
+		}
+		var entries *loader.NamePattern
+		if bf.EntryPattern != "" {
+			if entries, err = loader.CompileNamePattern(bf.EntryPattern); err != nil {
+				return nil, err
+			}
+		}
+		for _, f := range found {
+			f.Format = declared[f.Name].Format
+			f.Profile = declared[f.Name].Profile
+			f.EntryPattern = entries
+			f.EntryProfiles = profiles
+			out = append(out, f)
+		}
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no bank statement files given")
+	}
+	return out, nil
+}
+
+// declaredBanks mengindeks bank konfigurasi berdasarkan nama.
+func declaredBanks(banks []bankConfig) map[string]bankConfig {
+	out := make(map[string]bankConfig, len(banks))
+	for _, b := range banks {
+		out[b.Name] = b
+	}
+	return out
+}
+
+// banksFromFlags menyusun daftar bank dari flag --bank. Hanya bank yang diberi
+// path oleh flag yang dikembalikan; format dan profile kolomnya diambil dari
+// bank dengan nama yang sama di declared.
+func banksFromFlags(values []string, declared map[string]bankConfig) ([]bankConfig, error) {
+	var out []bankConfig
+	index := map[string]int{}
+	for _, v := range values {
+		in, err := parseBankFlag(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --bank %q: %w", v, err)
+		}
+		if i, ok := index[in.Name]; ok {
+			out[i].Paths = append(out[i].Paths, in.Path)
+			continue
+		}
+		index[in.Name] = len(out)
+		d := declared[in.Name]
+		out = append(out, bankConfig{Name: in.Name, Format: d.Format, Profile: d.Profile, Paths: []string{in.Path}})
+	}
+	return out, nil
+}
+
+// systemSource memilih sumber transaksi sistem: stdin untuk path "-", selain itu file.
+func systemSource(p string, profile loader.SystemProfile, stdin io.Reader) loader.SystemSource {
+	if p == "-" {
+		return loader.NewSystemCSVReader(stdin, profile)
+	}
+	return loader.NewSystemCSVFile(p, profile)
+}
+
+func mustLoadSystem(src loader.SystemSource) []model.SystemTransaction {
+	txs, err := src.SystemTransactions()
 	if err != nil {
 		log.Fatalf("failed to load system CSV: %v", err)
 	}
 	return txs
 }
 
-func mustLoadBanks(paths []string) map[string][]loader.BankStatement {
-	out := make(map[string][]loader.BankStatement)
-	for _, p := range paths {
-		name := bankNameFromPath(p)
-		bs, err := loader.LoadBankCSV(p, name)
+// mustLoadBanks memuat semua file bank secara konkuren dan menggabungkan
+// statement dari file-file yang dideklarasikan dengan nama bank yang sama.
+// Saldo statement ikut dikembalikan untuk verifikasi.
+func mustLoadBanks(files []loader.BankFile) (map[string][]loader.BankStatement, []loader.StatementBalance) {
+	out, bals, err := loader.LoadBankFilesWithBalances(files, 0)
+	if err != nil {
+		log.Fatalf("failed to load bank statements: %v", err)
+	}
+	return out, bals
+}
+
+// checkBalanceBreaks mengembalikan error bila strict aktif dan ada statement
+// bank yang saldonya tidak konsisten.
+func checkBalanceBreaks(res model.Result, strict bool) error {
+	if !strict || len(res.Details.BalanceBreaks) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%d bank statement balance breaks (see details.balance_breaks)", len(res.Details.BalanceBreaks))
+}
+
+// writeOutputs menulis hasil (Result atau laporan subcommand) ke setiap
+// tujuan output.
+func writeOutputs(res interface{}, outputs []outputConfig) error {
+	for _, o := range outputs {
+		if o.Path == "" || o.Path == "-" {
+			if err := writeJSON(os.Stdout, res); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Create(o.Path)
 		if err != nil {
-			log.Fatalf("failed to load bank CSV %s: %v", p, err)
+			return err
+		}
+		if err := writeJSON(f, res); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
 		}
-		out[name] = bs
 	}
-	return out
+	return nil
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parseBankFlag mengurai nilai --bank berbentuk "name=path" atau "path".
+// Tanpa nama eksplisit, nama bank diambil dari nama file (tanpa ekstensi).
+func parseBankFlag(v string) (loader.BankFile, error) {
+	if name, path, ok := strings.Cut(v, "="); ok && !strings.ContainsAny(name, `/\`) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return loader.BankFile{}, fmt.Errorf("empty bank name")
+		}
+		if path == "" {
+			return loader.BankFile{}, fmt.Errorf("empty path for bank %q", name)
+		}
+		return loader.BankFile{Name: name, Path: path}, nil
+	}
+	if v == "" {
+		return loader.BankFile{}, fmt.Errorf("empty path")
+	}
+	return loader.BankFile{Name: bankNameFromPath(v), Path: v}, nil
 }
 
 func bankNameFromPath(p string) string {
 	base := filepath.Base(p)
+	if strings.EqualFold(filepath.Ext(base), ".gz") {
+		base = base[:len(base)-len(".gz")]
+	}
 	ext := filepath.Ext(base)
 	return base[:len(base)-len(ext)]
 }
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"amartha/internal/loader"
+	"amartha/internal/reconcile"
+)
+
+func TestParseBankFlag(t *testing.T) {
+	cases := []struct {
+		in   string
+		name string
+		path string
+		ok   bool
+	}{
+		{"./testdata/bankA.csv", "bankA", "./testdata/bankA.csv", true},
+		{"./in/bankB.csv.gz", "bankB", "./in/bankB.csv.gz", true},
+		{"bankA=./in/bankA_2025-06.csv", "bankA", "./in/bankA_2025-06.csv", true},
+		{"bankA=./in/a=b.csv", "bankA", "./in/a=b.csv", true},
+		{"./in/x=y/bankB.csv", "bankB", "./in/x=y/bankB.csv", true},
+		{"=./in/bankA.csv", "", "", false},
+		{"bankA=", "", "", false},
+		{"", "", "", false},
+	}
+	for _, c := range cases {
+		got, err := parseBankFlag(c.in)
+		if c.ok {
+			if err != nil || got.Name != c.name || got.Path != c.path {
+				t.Fatalf("parseBankFlag(%q) => %+v,%v", c.in, got, err)
+			}
+		} else if err == nil {
+			t.Fatalf("expected error for %q", c.in)
+		}
+	}
+}
+
+func TestMustLoadBanks_MergesSameName(t *testing.T) {
+	got, bals := mustLoadBanks([]loader.BankFile{
+		{Name: "bankA", Path: "../../testdata/bankA.csv"},
+		{Name: "bankA", Path: "../../testdata/bankB.csv"},
+	})
+	if len(bals) != 0 {
+		t.Fatalf("expected no balances for plain csv, got %+v", bals)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 bank, got %d", len(got))
+	}
+	if len(got["bankA"]) != 6 {
+		t.Fatalf("expected 6 merged statements, got %d", len(got["bankA"]))
+	}
+	for _, b := range got["bankA"] {
+		if b.BankName != "bankA" {
+			t.Fatalf("unexpected bank name: %+v", b)
+		}
+	}
+}
+
+func TestSystemSource_Stdin(t *testing.T) {
+	stdin := strings.NewReader("trxID,amount,type,transactionTime\nTRX-1,1000,CREDIT,2025-06-01T00:00:00Z\n")
+	txs := mustLoadSystem(systemSource("-", loader.SystemProfile{}, stdin))
+	if len(txs) != 1 || txs[0].TrxID != "TRX-1" {
+		t.Fatalf("unexpected transactions: %+v", txs)
+	}
+}
+
+func TestCheckBalanceBreaks(t *testing.T) {
+	// Baris tidak urut; saldo 1 Juni konsisten, saldo 2 Juni tidak.
+	bank := writeConfig(t, "bankA.csv", "unique_identifier,amount,date,saldo\n"+
+		"BA-4,-5000,2025-06-02,1171000\n"+
+		"BA-2,-125000,2025-06-01,1125000\n"+
+		"BA-3,50000,2025-06-02,1175000\n"+
+		"BA-1,250000,2025-06-01,1250000\n")
+	profile := loader.BankProfile{BalanceColumn: "saldo"}
+	banks, bals := mustLoadBanks([]loader.BankFile{{Name: "bankA", Path: bank, Profile: profile}})
+	opts := reconcile.DefaultOptions()
+	opts.Balances = bals
+	day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	res, err := reconcile.ReconcileWithOptions(nil, banks, day, day.AddDate(0, 0, 1), opts)
+	if err != nil {
+		t.Fatalf("ReconcileWithOptions error: %v", err)
+	}
+	if len(res.Details.BalanceBreaks) != 1 || res.Details.BalanceBreaks[0].Date != "2025-06-02" {
+		t.Fatalf("expected a single break on 2025-06-02, got %+v", res.Details.BalanceBreaks)
+	}
+	if err := checkBalanceBreaks(res, false); err != nil {
+		t.Fatalf("unexpected error without strict mode: %v", err)
+	}
+	if err := checkBalanceBreaks(res, true); err == nil {
+		t.Fatalf("expected error in strict mode")
+	}
+}
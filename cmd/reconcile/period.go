@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// resolvePeriod mengubah preset periode menjadi rentang tanggal inklusif
+// (00:00 UTC) berdasarkan kalender pada zona loc saat now.
+//
+//	today          hari ini
+//	yesterday      kemarin
+//	last-<N>d      N hari penuh terakhir, berakhir kemarin (mis. last-7d)
+//	month-to-date  tanggal 1 bulan berjalan s.d. hari ini
+//	last-month     seluruh bulan sebelumnya
+func resolvePeriod(name string, now time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	local := now.In(loc)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	p := strings.ToLower(strings.TrimSpace(name))
+	switch p {
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		y := today.AddDate(0, 0, -1)
+		return y, y, nil
+	case "month-to-date":
+		return time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC), today, nil
+	case "last-month":
+		firstOfMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1), nil
+	}
+	if strings.HasPrefix(p, "last-") && strings.HasSuffix(p, "d") {
+		n, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(p, "last-"), "d"))
+		if err == nil && n > 0 {
+			return today.AddDate(0, 0, -n), today.AddDate(0, 0, -1), nil
+		}
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown period %q: expected today, yesterday, last-<N>d, month-to-date or last-month", name)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResolvePeriod(t *testing.T) {
+	wib := time.FixedZone("WIB", 7*3600)
+	// 2025-07-01 18:30 UTC = 2025-07-02 01:30 WIB.
+	now := time.Date(2025, 7, 1, 18, 30, 0, 0, time.UTC)
+	cases := []struct {
+		period     string
+		loc        *time.Location
+		start, end string
+	}{
+		{"today", time.UTC, "2025-07-01", "2025-07-01"},
+		{"today", wib, "2025-07-02", "2025-07-02"},
+		{"yesterday", wib, "2025-07-01", "2025-07-01"},
+		{"last-7d", wib, "2025-06-25", "2025-07-01"},
+		{"month-to-date", wib, "2025-07-01", "2025-07-02"},
+		{"last-month", time.UTC, "2025-06-01", "2025-06-30"},
+		{"last-month", wib, "2025-06-01", "2025-06-30"},
+	}
+	for _, c := range cases {
+		start, end, err := resolvePeriod(c.period, now, c.loc)
+		if err != nil {
+			t.Fatalf("resolvePeriod(%q) error: %v", c.period, err)
+		}
+		if !start.Equal(mustDate(t, c.start)) || !end.Equal(mustDate(t, c.end)) {
+			t.Fatalf("resolvePeriod(%q, %s) => %s..%s, want %s..%s", c.period, c.loc, start.Format("2006-01-02"), end.Format("2006-01-02"), c.start, c.end)
+		}
+	}
+	for _, bad := range []string{"", "last-week", "last-0d", "last-xd"} {
+		if _, _, err := resolvePeriod(bad, now, time.UTC); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestParseJob_PeriodAndDate(t *testing.T) {
+	now := time.Date(2025, 3, 15, 10, 0, 0, 0, time.UTC)
+	base := []string{"--system", "s.csv", "--bank", "a.csv"}
+
+	j, err := parseJob(append(base, "--period", "last-month"), now)
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if !j.Start.Equal(mustDate(t, "2025-02-01")) || !j.End.Equal(mustDate(t, "2025-02-28")) {
+		t.Fatalf("unexpected range: %s - %s", j.Start, j.End)
+	}
+
+	j, err = parseJob(append(base, "--date", "2025-06-01"), now)
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if !j.Start.Equal(mustDate(t, "2025-06-01")) || !j.End.Equal(mustDate(t, "2025-06-01")) {
+		t.Fatalf("unexpected range: %s - %s", j.Start, j.End)
+	}
+
+	if _, err := parseJob(append(base, "--period", "yesterday", "--start", "2025-03-01"), now); err == nil {
+		t.Fatalf("expected error combining --period and --start")
+	}
+
+	p := writeConfig(t, "job.json", `{"system": {"path": "s.csv"}, "banks": [{"name": "a", "paths": ["a.csv"]}], "date_range": {"period": "month-to-date"}}`)
+	j, err = parseJob([]string{"--config", p}, now)
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if !j.Start.Equal(mustDate(t, "2025-03-01")) || !j.End.Equal(mustDate(t, "2025-03-15")) {
+		t.Fatalf("unexpected range: %s - %s", j.Start, j.End)
+	}
+	// --start/--end di flag menggantikan period dari konfigurasi.
+	j, err = parseJob([]string{"--config", p, "--start", "2025-03-10", "--end", "2025-03-11"}, now)
+	if err != nil {
+		t.Fatalf("parseJob error: %v", err)
+	}
+	if !j.Start.Equal(mustDate(t, "2025-03-10")) || !j.End.Equal(mustDate(t, "2025-03-11")) {
+		t.Fatalf("unexpected range: %s - %s", j.Start, j.End)
+	}
+}
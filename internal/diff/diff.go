@@ -0,0 +1,187 @@
+// Package diff membandingkan dua model.Result, mis. hasil dua set opsi
+// matching atas input yang sama, atau hasil kemarin dengan rerun hari ini
+// setelah file bank dikoreksi.
+package diff
+
+import (
+	"reflect"
+	"sort"
+
+	"amartha/internal/model"
+)
+
+// Report adalah perbedaan hasil After terhadap Before.
+type Report struct {
+	Summary SummaryDelta `json:"summary"`
+	// Unchanged adalah jumlah pasangan yang identik di kedua hasil.
+	Unchanged int `json:"unchanged"`
+	// Added adalah pasangan baru untuk transaksi sistem yang sebelumnya tidak matched.
+	Added []model.MatchedPair `json:"added"`
+	// Removed adalah pasangan yang hilang; transaksi sistemnya tidak matched lagi.
+	Removed []model.MatchedPair `json:"removed"`
+	// Repaired adalah transaksi sistem yang matched di kedua hasil tetapi
+	// dengan statement bank yang berbeda.
+	Repaired []Repair `json:"repaired"`
+	// NewlyUnmatched adalah record yang unmatched di After tetapi tidak di Before.
+	NewlyUnmatched Records `json:"newly_unmatched"`
+	// NewlyCleared adalah record yang unmatched di Before tetapi tidak lagi di
+	// After (kini matched atau tidak ada lagi di input).
+	NewlyCleared Records `json:"newly_cleared"`
+}
+
+// Records adalah record unmatched sistem dan bank (per nama bank).
+type Records struct {
+	System []model.NormalizedRecord            `json:"system"`
+	Bank   map[string][]model.NormalizedRecord `json:"bank"`
+}
+
+// SummaryDelta adalah ringkasan kedua hasil dan selisihnya (After - Before).
+type SummaryDelta struct {
+	Before model.Summary `json:"before"`
+	After  model.Summary `json:"after"`
+	Delta  model.Summary `json:"delta"`
+}
+
+// Repair adalah transaksi sistem yang dipasangkan ulang.
+type Repair struct {
+	SystemID string            `json:"system_id"`
+	Before   model.MatchedPair `json:"before"`
+	After    model.MatchedPair `json:"after"`
+}
+
+// Results membandingkan pasangan matched, record unmatched, dan ringkasan
+// before dengan after. Pasangan diidentifikasi dengan SystemID, BankName, dan
+// BankID, record dengan ID (per bank); hasil diurutkan sehingga laporan
+// deterministik.
+func Results(before, after model.Result) Report {
+	rep := Report{
+		Summary: SummaryDelta{
+			Before: before.Summary,
+			After:  after.Summary,
+			Delta:  subtract(after.Summary, before.Summary),
+		},
+		Added:    []model.MatchedPair{},
+		Removed:  []model.MatchedPair{},
+		Repaired: []Repair{},
+		NewlyUnmatched: Records{
+			System: missing(after.Details.UnmatchedSystem, before.Details.UnmatchedSystem),
+			Bank:   missingByBank(after.Details.UnmatchedBankByGroup, before.Details.UnmatchedBankByGroup),
+		},
+		NewlyCleared: Records{
+			System: missing(before.Details.UnmatchedSystem, after.Details.UnmatchedSystem),
+			Bank:   missingByBank(before.Details.UnmatchedBankByGroup, after.Details.UnmatchedBankByGroup),
+		},
+	}
+
+	inAfter := map[pairKey]int{}
+	for _, p := range after.Details.Matched {
+		inAfter[keyOf(p)]++
+	}
+	var gone []model.MatchedPair
+	for _, p := range before.Details.Matched {
+		if k := keyOf(p); inAfter[k] > 0 {
+			inAfter[k]--
+			rep.Unchanged++
+			continue
+		}
+		gone = append(gone, p)
+	}
+	inBefore := map[pairKey]int{}
+	for _, p := range before.Details.Matched {
+		inBefore[keyOf(p)]++
+	}
+	// Kelompokkan pasangan baru per transaksi sistem agar pasangan ulang
+	// dapat dikenali.
+	fresh := map[string][]model.MatchedPair{}
+	for _, p := range after.Details.Matched {
+		if k := keyOf(p); inBefore[k] > 0 {
+			inBefore[k]--
+			continue
+		}
+		fresh[p.SystemID] = append(fresh[p.SystemID], p)
+	}
+
+	sortPairs(gone)
+	for _, p := range gone {
+		if c := fresh[p.SystemID]; len(c) > 0 {
+			sortPairs(c)
+			rep.Repaired = append(rep.Repaired, Repair{SystemID: p.SystemID, Before: p, After: c[0]})
+			fresh[p.SystemID] = c[1:]
+			continue
+		}
+		rep.Removed = append(rep.Removed, p)
+	}
+	for _, c := range fresh {
+		rep.Added = append(rep.Added, c...)
+	}
+	sortPairs(rep.Added)
+	return rep
+}
+
+// missing mengembalikan record di a yang ID-nya tidak ada di b (dihitung
+// per kemunculan), diurutkan berdasarkan ID lalu tanggal.
+func missing(a, b []model.NormalizedRecord) []model.NormalizedRecord {
+	left := map[string]int{}
+	for _, r := range b {
+		left[r.ID]++
+	}
+	out := []model.NormalizedRecord{}
+	for _, r := range a {
+		if left[r.ID] > 0 {
+			left[r.ID]--
+			continue
+		}
+		out = append(out, r)
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].ID != out[j].ID {
+			return out[i].ID < out[j].ID
+		}
+		return out[i].Date.Before(out[j].Date)
+	})
+	return out
+}
+
+// missingByBank menerapkan missing per bank; bank tanpa perubahan dihilangkan.
+func missingByBank(a, b map[string][]model.NormalizedRecord) map[string][]model.NormalizedRecord {
+	out := map[string][]model.NormalizedRecord{}
+	for bank, recs := range a {
+		if m := missing(recs, b[bank]); len(m) > 0 {
+			out[bank] = m
+		}
+	}
+	return out
+}
+
+type pairKey struct{ systemID, bankName, bankID string }
+
+func keyOf(p model.MatchedPair) pairKey { return pairKey{p.SystemID, p.BankName, p.BankID} }
+
+func sortPairs(ps []model.MatchedPair) {
+	sort.SliceStable(ps, func(i, j int) bool {
+		a, b := keyOf(ps[i]), keyOf(ps[j])
+		if a.systemID != b.systemID {
+			return a.systemID < b.systemID
+		}
+		if a.bankName != b.bankName {
+			return a.bankName < b.bankName
+		}
+		return a.bankID < b.bankID
+	})
+}
+
+// subtract mengurangkan setiap field numerik Summary (a - b), sehingga field
+// baru ikut terhitung tanpa perlu didaftarkan di sini.
+func subtract(a, b model.Summary) model.Summary {
+	var out model.Summary
+	va, vb, vo := reflect.ValueOf(a), reflect.ValueOf(b), reflect.ValueOf(&out).Elem()
+	for i := 0; i < vo.NumField(); i++ {
+		switch f := vo.Field(i); f.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			f.SetInt(va.Field(i).Int() - vb.Field(i).Int())
+		case reflect.Float32, reflect.Float64:
+			f.SetFloat(va.Field(i).Float() - vb.Field(i).Float())
+		}
+	}
+	return out
+}
@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"amartha/internal/model"
+)
+
+func pair(sys, bank, id string) model.MatchedPair {
+	return model.MatchedPair{SystemID: sys, BankName: bank, BankID: id}
+}
+
+func TestResults(t *testing.T) {
+	before := model.Result{
+		Summary: model.Summary{TotalProcessed: 10, TotalMatched: 3, TotalUnmatched: 4, TotalDiscrepancies: 1000},
+		Details: model.Details{Matched: []model.MatchedPair{
+			pair("TRX-1", "bankA", "BA-1"),
+			pair("TRX-2", "bankA", "BA-2"),
+			pair("TRX-3", "bankB", "BB-1"),
+		}},
+	}
+	after := model.Result{
+		Summary: model.Summary{TotalProcessed: 10, TotalMatched: 3, TotalUnmatched: 4, TotalDiscrepancies: 4000},
+		Details: model.Details{Matched: []model.MatchedPair{
+			pair("TRX-4", "bankB", "BB-2"),
+			pair("TRX-2", "bankA", "BA-3"),
+			pair("TRX-1", "bankA", "BA-1"),
+		}},
+	}
+
+	rep := Results(before, after)
+	if rep.Unchanged != 1 {
+		t.Fatalf("expected 1 unchanged pair, got %d", rep.Unchanged)
+	}
+	if len(rep.Added) != 1 || rep.Added[0] != pair("TRX-4", "bankB", "BB-2") {
+		t.Fatalf("unexpected added: %+v", rep.Added)
+	}
+	if len(rep.Removed) != 1 || rep.Removed[0] != pair("TRX-3", "bankB", "BB-1") {
+		t.Fatalf("unexpected removed: %+v", rep.Removed)
+	}
+	if len(rep.Repaired) != 1 || rep.Repaired[0].SystemID != "TRX-2" ||
+		rep.Repaired[0].Before.BankID != "BA-2" || rep.Repaired[0].After.BankID != "BA-3" {
+		t.Fatalf("unexpected repaired: %+v", rep.Repaired)
+	}
+	if rep.Summary.Delta != (model.Summary{TotalDiscrepancies: 3000}) || rep.Summary.Before != before.Summary {
+		t.Fatalf("unexpected summary delta: %+v", rep.Summary)
+	}
+
+	same := Results(before, before)
+	if same.Unchanged != 3 || len(same.Added)+len(same.Removed)+len(same.Repaired) != 0 {
+		t.Fatalf("expected no changes comparing a result with itself: %+v", same)
+	}
+}
+
+func TestResults_Records(t *testing.T) {
+	d := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	rec := func(id string) model.NormalizedRecord { return model.NormalizedRecord{ID: id, Date: d, Amount: 1000} }
+	before := model.Result{Details: model.Details{
+		UnmatchedSystem:      []model.NormalizedRecord{rec("TRX-2"), rec("TRX-1")},
+		UnmatchedBankByGroup: map[string][]model.NormalizedRecord{"bankA": {rec("BA-1")}, "bankB": {rec("BB-1")}},
+	}}
+	after := model.Result{Details: model.Details{
+		UnmatchedSystem:      []model.NormalizedRecord{rec("TRX-3"), rec("TRX-1")},
+		UnmatchedBankByGroup: map[string][]model.NormalizedRecord{"bankA": {rec("BA-1"), rec("BA-2")}},
+	}}
+
+	rep := Results(before, after)
+	if len(rep.NewlyUnmatched.System) != 1 || rep.NewlyUnmatched.System[0].ID != "TRX-3" {
+		t.Fatalf("unexpected newly unmatched system: %+v", rep.NewlyUnmatched.System)
+	}
+	if len(rep.NewlyUnmatched.Bank) != 1 || len(rep.NewlyUnmatched.Bank["bankA"]) != 1 || rep.NewlyUnmatched.Bank["bankA"][0].ID != "BA-2" {
+		t.Fatalf("unexpected newly unmatched bank: %+v", rep.NewlyUnmatched.Bank)
+	}
+	if len(rep.NewlyCleared.System) != 1 || rep.NewlyCleared.System[0].ID != "TRX-2" {
+		t.Fatalf("unexpected newly cleared system: %+v", rep.NewlyCleared.System)
+	}
+	if len(rep.NewlyCleared.Bank) != 1 || len(rep.NewlyCleared.Bank["bankB"]) != 1 {
+		t.Fatalf("unexpected newly cleared bank: %+v", rep.NewlyCleared.Bank)
+	}
+}
+
+func TestSubtract_AllFields(t *testing.T) {
+	var a, b model.Summary
+	va, vb := reflect.ValueOf(&a).Elem(), reflect.ValueOf(&b).Elem()
+	for i := 0; i < va.NumField(); i++ {
+		switch va.Field(i).Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			va.Field(i).SetInt(int64(i + 3))
+			vb.Field(i).SetInt(1)
+		case reflect.Float32, reflect.Float64:
+			va.Field(i).SetFloat(float64(i + 3))
+			vb.Field(i).SetFloat(1)
+		default:
+			t.Fatalf("Summary.%s is not numeric; subtract cannot compute its delta", va.Type().Field(i).Name)
+		}
+	}
+	d := reflect.ValueOf(subtract(a, b))
+	for i := 0; i < d.NumField(); i++ {
+		if got := d.Field(i).Convert(reflect.TypeOf(float64(0))).Float(); got != float64(i+2) {
+			t.Fatalf("Summary.%s delta = %v, want %d", d.Type().Field(i).Name, got, i+2)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// Package eval menilai kualitas matching sebuah model.Result terhadap
+// ground truth pasangan system-bank yang benar.
+package eval
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"amartha/internal/model"
+)
+
+// Pair adalah pasangan transaksi sistem dan statement bank.
+type Pair struct {
+	SystemID string `json:"system_id"`
+	BankName string `json:"bank_name"`
+	BankID   string `json:"bank_id"`
+	Category string `json:"category,omitempty"` // kategori ground truth, mis. exact, fee, lag
+}
+
+func (p Pair) key() string { return p.SystemID + "\x00" + p.BankName + "\x00" + p.BankID }
+
+// Report adalah hasil penilaian satu Result.
+type Report struct {
+	Label          string  `json:"label"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	Missed         int     `json:"missed"` // pasangan ground truth yang tidak matched
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	F1             float64 `json:"f1"`
+	// RecallByCategory adalah recall per kategori ground truth.
+	RecallByCategory map[string]float64 `json:"recall_by_category,omitempty"`
+	// FalsePositivePairs dan MissedPairs hanya diisi jika diminta.
+	FalsePositivePairs []Pair `json:"false_positive_pairs,omitempty"`
+	MissedPairs        []Pair `json:"missed_pairs,omitempty"`
+}
+
+// LoadGroundTruth membaca file ground truth CSV dengan header
+// trxID,bank_name,unique_identifier[,category] (format keluaran
+// gen-testdata). Baris tanpa trxID atau unique_identifier (record yang memang
+// tidak memiliki pasangan) dilewati.
+func LoadGroundTruth(path string) ([]Pair, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	pairs, err := ReadGroundTruth(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return pairs, nil
+}
+
+// ReadGroundTruth membaca ground truth dari reader (lihat LoadGroundTruth).
+func ReadGroundTruth(r io.Reader) ([]Pair, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	col := map[string]int{}
+	for i, h := range header {
+		col[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, name := range []string{"trxid", "bank_name", "unique_identifier"} {
+		if _, ok := col[name]; !ok {
+			return nil, fmt.Errorf("column %q not found in header %v", name, header)
+		}
+	}
+	field := func(rec []string, name string) string {
+		i, ok := col[name]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var out []Pair
+	seen := map[string]bool{}
+	for line := 2; ; line++ {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p := Pair{
+			SystemID: field(rec, "trxid"),
+			BankName: field(rec, "bank_name"),
+			BankID:   field(rec, "unique_identifier"),
+			Category: field(rec, "category"),
+		}
+		if p.SystemID == "" || p.BankID == "" {
+			continue
+		}
+		if seen[p.key()] {
+			return nil, fmt.Errorf("line %d: duplicate pair %s/%s/%s", line, p.SystemID, p.BankName, p.BankID)
+		}
+		seen[p.key()] = true
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+// Score membandingkan pasangan matched di res dengan truth. Jika details
+// true, daftar false positive dan missed ikut dilaporkan (diurutkan).
+func Score(label string, res model.Result, truth []Pair, details bool) Report {
+	want := make(map[string]Pair, len(truth))
+	total := map[string]int{}
+	for _, p := range truth {
+		want[p.key()] = p
+		total[p.Category]++
+	}
+
+	rep := Report{Label: label}
+	found := map[string]bool{}
+	hit := map[string]int{}
+	for _, m := range res.Details.Matched {
+		p := Pair{SystemID: m.SystemID, BankName: m.BankName, BankID: m.BankID}
+		if t, ok := want[p.key()]; ok {
+			rep.TruePositives++
+			found[p.key()] = true
+			hit[t.Category]++
+			continue
+		}
+		rep.FalsePositives++
+		if details {
+			rep.FalsePositivePairs = append(rep.FalsePositivePairs, p)
+		}
+	}
+	for _, p := range truth {
+		if found[p.key()] {
+			continue
+		}
+		rep.Missed++
+		if details {
+			rep.MissedPairs = append(rep.MissedPairs, p)
+		}
+	}
+
+	rep.Precision = ratio(rep.TruePositives, rep.TruePositives+rep.FalsePositives)
+	rep.Recall = ratio(rep.TruePositives, rep.TruePositives+rep.Missed)
+	if rep.Precision+rep.Recall > 0 {
+		rep.F1 = 2 * rep.Precision * rep.Recall / (rep.Precision + rep.Recall)
+	}
+	if len(total) > 1 || total[""] == 0 {
+		rep.RecallByCategory = map[string]float64{}
+		for c, n := range total {
+			if c != "" {
+				rep.RecallByCategory[c] = ratio(hit[c], n)
+			}
+		}
+	}
+	sortPairs(rep.FalsePositivePairs)
+	sortPairs(rep.MissedPairs)
+	return rep
+}
+
+// ratio mengembalikan a/b; 1 jika b nol (tidak ada yang perlu dinilai).
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return 1
+	}
+	return float64(a) / float64(b)
+}
+
+func sortPairs(ps []Pair) {
+	sort.Slice(ps, func(i, j int) bool { return ps[i].key() < ps[j].key() })
+}
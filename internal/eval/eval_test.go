@@ -0,0 +1,79 @@
+package eval
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"amartha/internal/model"
+)
+
+const truthCSV = "trxID,bank_name,unique_identifier,category\n" +
+	"TRX-1,bankA,BA-1,exact\n" +
+	"TRX-2,bankA,BA-2,fee\n" +
+	"TRX-3,bankB,BB-1,lag\n" +
+	"TRX-4,,,missing_bank\n" +
+	",bankB,BB-9,bank_only\n"
+
+func TestReadGroundTruth(t *testing.T) {
+	got, err := ReadGroundTruth(strings.NewReader(truthCSV))
+	if err != nil {
+		t.Fatalf("ReadGroundTruth error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 pairs (unpaired rows skipped), got %+v", got)
+	}
+	if got[2] != (Pair{SystemID: "TRX-3", BankName: "bankB", BankID: "BB-1", Category: "lag"}) {
+		t.Fatalf("unexpected pair: %+v", got[2])
+	}
+
+	bad := map[string]string{
+		"missing column": "trxID,unique_identifier\nTRX-1,BA-1\n",
+		"duplicate":      "trxID,bank_name,unique_identifier\nTRX-1,bankA,BA-1\nTRX-1,bankA,BA-1\n",
+		"empty":          "",
+	}
+	for name, in := range bad {
+		if _, err := ReadGroundTruth(strings.NewReader(in)); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	truth, err := ReadGroundTruth(strings.NewReader(truthCSV))
+	if err != nil {
+		t.Fatalf("ReadGroundTruth error: %v", err)
+	}
+	var res model.Result
+	res.Details.Matched = []model.MatchedPair{
+		{SystemID: "TRX-1", BankName: "bankA", BankID: "BA-1"},
+		{SystemID: "TRX-2", BankName: "bankA", BankID: "BA-3"}, // pasangan salah
+		{SystemID: "TRX-3", BankName: "bankB", BankID: "BB-1"},
+	}
+
+	rep := Score("amount", res, truth, true)
+	if rep.Label != "amount" || rep.TruePositives != 2 || rep.FalsePositives != 1 || rep.Missed != 1 {
+		t.Fatalf("unexpected counts: %+v", rep)
+	}
+	if !approx(rep.Precision, 2.0/3) || !approx(rep.Recall, 2.0/3) || !approx(rep.F1, 2.0/3) {
+		t.Fatalf("unexpected precision/recall/f1: %+v", rep)
+	}
+	if rep.RecallByCategory["exact"] != 1 || rep.RecallByCategory["fee"] != 0 || rep.RecallByCategory["lag"] != 1 {
+		t.Fatalf("unexpected recall by category: %+v", rep.RecallByCategory)
+	}
+	if len(rep.FalsePositivePairs) != 1 || rep.FalsePositivePairs[0].BankID != "BA-3" {
+		t.Fatalf("unexpected false positives: %+v", rep.FalsePositivePairs)
+	}
+	if len(rep.MissedPairs) != 1 || rep.MissedPairs[0].SystemID != "TRX-2" || rep.MissedPairs[0].Category != "fee" {
+		t.Fatalf("unexpected missed pairs: %+v", rep.MissedPairs)
+	}
+
+	if rep := Score("amount", res, truth, false); rep.FalsePositivePairs != nil || rep.MissedPairs != nil {
+		t.Fatalf("expected no pair lists without details: %+v", rep)
+	}
+	if rep := Score("empty", model.Result{}, nil, false); rep.Precision != 1 || rep.Recall != 1 {
+		t.Fatalf("expected perfect score for empty input: %+v", rep)
+	}
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
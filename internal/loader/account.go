@@ -0,0 +1,60 @@
+package loader
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// AccountRule menurunkan bank/rekening tujuan transaksi sistem. Semua kriteria
+// yang diisi harus cocok; aturan pertama yang cocok dipakai.
+type AccountRule struct {
+	IDPattern string `json:"id_pattern"` // regexp atas trxID
+	Type      string `json:"type"`       // CREDIT atau DEBIT
+	Account   string `json:"account"`    // nama bank atau nomor rekening
+}
+
+// accountRouter adalah AccountRules yang sudah dikompilasi.
+type accountRouter []compiledAccountRule
+
+type compiledAccountRule struct {
+	id  *regexp.Regexp
+	typ string
+	acc string
+}
+
+func compileAccountRules(rules []AccountRule) (accountRouter, error) {
+	out := make(accountRouter, 0, len(rules))
+	for i, r := range rules {
+		if strings.TrimSpace(r.Account) == "" {
+			return nil, fmt.Errorf("account_rules[%d]: account is required", i)
+		}
+		if r.IDPattern == "" && r.Type == "" {
+			return nil, fmt.Errorf("account_rules[%d]: id_pattern or type is required", i)
+		}
+		c := compiledAccountRule{typ: r.Type, acc: strings.TrimSpace(r.Account)}
+		if r.IDPattern != "" {
+			re, err := regexp.Compile(r.IDPattern)
+			if err != nil {
+				return nil, fmt.Errorf("account_rules[%d]: invalid id_pattern: %w", i, err)
+			}
+			c.id = re
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
+
+// account mengembalikan rekening dari aturan pertama yang cocok, atau "".
+func (r accountRouter) account(id, typ string) string {
+	for _, c := range r {
+		if c.id != nil && !c.id.MatchString(id) {
+			continue
+		}
+		if c.typ != "" && !strings.EqualFold(c.typ, typ) {
+			continue
+		}
+		return c.acc
+	}
+	return ""
+}
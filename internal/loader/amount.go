@@ -0,0 +1,68 @@
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseDecimal mengubah amount desimal (mis. "250000.00" atau "250000,00")
+// menjadi Rupiah integer. Bagian pecahan harus nol karena amount disimpan
+// tanpa desimal.
+func parseDecimal(s string, decimalSep byte) (int64, error) {
+	s = strings.TrimSpace(s)
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, decimalSep); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if strings.Trim(frac, "0") != "" {
+		return 0, fmt.Errorf("fractional amount %q not supported", s)
+	}
+	if whole == "" || whole == "-" || whole == "+" {
+		if frac == "" {
+			return 0, fmt.Errorf("empty amount %q", s)
+		}
+		whole += "0"
+	}
+	return strconv.ParseInt(whole, 10, 64)
+}
+
+// parseLocaleAmount mengurai amount sesuai locale: LocaleID ("1.250.000,00"),
+// LocaleEN ("1,250,000.00"), atau kosong untuk parseAmount default.
+// Amount dalam kurung, mis. "(5.000)", dianggap negatif.
+func parseLocaleAmount(s, locale string) (int64, error) {
+	var thousands, decimal byte
+	switch locale {
+	case "":
+		return parseAmount(s)
+	case LocaleID:
+		thousands, decimal = '.', ','
+	case LocaleEN:
+		thousands, decimal = ',', '.'
+	default:
+		return 0, fmt.Errorf("unknown locale %q", locale)
+	}
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		neg = true
+		s = s[1 : len(s)-1]
+	}
+	s = strings.Map(func(r rune) rune {
+		if r == rune(thousands) || r == ' ' || r == '\u00a0' {
+			return -1
+		}
+		return r
+	}, s)
+	v, err := parseDecimal(s, decimal)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		if v < 0 {
+			return 0, fmt.Errorf("invalid amount %q: double negative", s)
+		}
+		v = -v
+	}
+	return v, nil
+}
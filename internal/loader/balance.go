@@ -0,0 +1,111 @@
+package loader
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// StatementBalance adalah saldo awal dan akhir satu statement bank (per file
+// atau statement dan tanggal) beserta total mutasi yang dibaca di antaranya.
+type StatementBalance struct {
+	BankName  string
+	Source    string // path file; kosong untuk sumber reader
+	Statement string // ID statement (camt.053 Stmt/Id, MT940 :20:), kosong untuk CSV
+	Account   string
+	Date      time.Time // tanggal saldo akhir
+	Opening   int64
+	Closing   int64
+	Total     int64 // jumlah amount bertanda transaksi statement
+	Count     int   // jumlah transaksi statement
+}
+
+// Difference mengembalikan selisih saldo awal + total mutasi terhadap saldo
+// akhir; nol berarti statement konsisten.
+func (b StatementBalance) Difference() int64 { return b.Opening + b.Total - b.Closing }
+
+// BalanceSource adalah BankSource yang juga membaca saldo awal/akhir statement.
+// Format tanpa informasi saldo mengembalikan balances kosong.
+type BalanceSource interface {
+	BankSource
+	BankStatementsWithBalances() ([]BankStatement, []StatementBalance, error)
+}
+
+// LoadBanksWithBalances seperti LoadBanks tetapi juga mengumpulkan saldo dari
+// sources yang mengimplementasikan BalanceSource, berurutan sesuai sources.
+func LoadBanksWithBalances(sources []BankSource, workers int) (map[string][]BankStatement, []StatementBalance, error) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make([][]BankStatement, len(sources))
+	balances := make([][]StatementBalance, len(sources))
+	errs := make([]error, len(sources))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(sources); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if bs, ok := sources[i].(BalanceSource); ok {
+					results[i], balances[i], errs[i] = bs.BankStatementsWithBalances()
+				} else {
+					results[i], errs[i] = sources[i].BankStatements()
+				}
+			}
+		}()
+	}
+	for i := range sources {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	out := make(map[string][]BankStatement)
+	var outBalances []StatementBalance
+	for i, src := range sources {
+		if errs[i] != nil {
+			return nil, nil, fmt.Errorf("load bank %s: %w", src.BankName(), errs[i])
+		}
+		if len(results[i]) == 0 {
+			out[src.BankName()] = append(out[src.BankName()], results[i]...)
+		}
+		// Arsip dengan entri per bank dapat menghasilkan statement untuk
+		// beberapa bank sekaligus.
+		for _, st := range results[i] {
+			out[st.BankName] = append(out[st.BankName], st)
+		}
+		outBalances = append(outBalances, balances[i]...)
+	}
+	return out, outBalances, nil
+}
+
+// withAccount mengisi Account statement dan saldo yang belum memilikinya,
+// mis. dari nama entri zip berisi satu CSV per rekening.
+func withAccount(stmts []BankStatement, bals []StatementBalance, account string) {
+	for i := range stmts {
+		if stmts[i].Account == "" {
+			stmts[i].Account = account
+		}
+	}
+	for i := range bals {
+		if bals[i].Account == "" {
+			bals[i].Account = account
+		}
+	}
+}
+
+// withSource mengisi Source pada statement serta BankName dan Source pada
+// saldo hasil parsing satu stream.
+func withSource(stmts []BankStatement, bals []StatementBalance, bankName, source string) []StatementBalance {
+	for i := range stmts {
+		stmts[i].Source = source
+	}
+	for i := range bals {
+		bals[i].BankName = bankName
+		bals[i].Source = source
+	}
+	return bals
+}
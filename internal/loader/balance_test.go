@@ -0,0 +1,116 @@
+package loader
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestBalances_Camt053AndMT940(t *testing.T) {
+	_, camt, err := NewCamt053File("testdata/camt053_bankA.xml", "bankA").(BalanceSource).BankStatementsWithBalances()
+	if err != nil {
+		t.Fatalf("camt.053 error: %v", err)
+	}
+	// Statement kedua tidak memiliki <Bal> sehingga dilewati.
+	if len(camt) != 1 {
+		t.Fatalf("expected 1 camt.053 balance, got %+v", camt)
+	}
+	c := camt[0]
+	if c.BankName != "bankA" || c.Source != "testdata/camt053_bankA.xml" || c.Statement != "STMT-0601" ||
+		c.Opening != 500000 || c.Closing != 625000 || c.Total != 125000 || c.Count != 2 || c.Difference() != 0 ||
+		!c.Date.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected camt.053 balance: %+v", c)
+	}
+
+	_, mt, err := NewMT940File("testdata/mt940_bankB.sta", "bankB").(BalanceSource).BankStatementsWithBalances()
+	if err != nil {
+		t.Fatalf("MT940 error: %v", err)
+	}
+	if len(mt) != 2 {
+		t.Fatalf("expected 2 MT940 balances, got %+v", mt)
+	}
+	if mt[0].Statement != "STMT-0601" || mt[0].Account != "BANKIDJA/0001234567" || mt[0].Opening != 1000000 ||
+		mt[0].Closing != 1083000 || mt[0].Total != 83000 || mt[0].Count != 3 || mt[0].Difference() != 0 {
+		t.Fatalf("unexpected first MT940 balance: %+v", mt[0])
+	}
+	if mt[1].Statement != "STMT-0101" || mt[1].Opening != 0 || mt[1].Closing != 70000 || mt[1].Difference() != 0 ||
+		!mt[1].Date.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected second MT940 balance: %+v", mt[1])
+	}
+}
+
+func TestBalances_CSVRunningBalance(t *testing.T) {
+	content := "unique_identifier,amount,date,saldo\n" +
+		"BA-1,250000,2025-06-01,1250000\n" +
+		"BA-2,-125000,2025-06-01,1125000\n" +
+		"BA-3,50000,2025-06-02,1175000\n" +
+		"BA-4,-5000,2025-06-02,1100000\n"
+	src := NewBankCSVReader(bytes.NewBufferString(content), "bankA", BankProfile{BalanceColumn: "saldo"})
+	got, bals, err := src.(BalanceSource).BankStatementsWithBalances()
+	if err != nil {
+		t.Fatalf("BankStatementsWithBalances error: %v", err)
+	}
+	if len(got) != 4 || len(bals) != 2 {
+		t.Fatalf("unexpected result: %d statements, balances %+v", len(got), bals)
+	}
+	if bals[0].Opening != 1000000 || bals[0].Closing != 1125000 || bals[0].Difference() != 0 {
+		t.Fatalf("unexpected first balance: %+v", bals[0])
+	}
+	// Saldo akhir 2 Juni seharusnya 1.170.000.
+	if bals[1].Opening != 1125000 || bals[1].Closing != 1100000 || bals[1].Total != 45000 || bals[1].Difference() != 70000 {
+		t.Fatalf("unexpected second balance: %+v", bals[1])
+	}
+}
+
+func TestBalances_CSVRunningBalanceUnsorted(t *testing.T) {
+	// Statement terbaru lebih dulu (urutan menurun).
+	content := "unique_identifier,amount,date,saldo\n" +
+		"BA-4,-5000,2025-06-02,1170000\n" +
+		"BA-3,50000,2025-06-02,1175000\n" +
+		"BA-2,-125000,2025-06-01,1125000\n" +
+		"BA-1,250000,2025-06-01,1250000\n"
+	_, bals, err := readBankCSV(bytes.NewBufferString(content), "bankA", BankProfile{BalanceColumn: "saldo"})
+	if err != nil {
+		t.Fatalf("readBankCSV error: %v", err)
+	}
+	if len(bals) != 2 {
+		t.Fatalf("expected one balance per date, got %+v", bals)
+	}
+	for i, want := range [][2]int64{{1000000, 1125000}, {1125000, 1170000}} {
+		if bals[i].Opening != want[0] || bals[i].Closing != want[1] || bals[i].Difference() != 0 {
+			t.Fatalf("balance %d: got %+v, want opening %d closing %d", i, bals[i], want[0], want[1])
+		}
+	}
+
+	// Baris 1 Juni dan 2 Juni berselang-seling.
+	interleaved := "unique_identifier,amount,date,saldo\n" +
+		"BA-1,250000,2025-06-01,1250000\n" +
+		"BA-3,50000,2025-06-02,1175000\n" +
+		"BA-2,-125000,2025-06-01,1125000\n" +
+		"BA-4,-5000,2025-06-02,1170000\n"
+	_, bals, err = readBankCSV(bytes.NewBufferString(interleaved), "bankA", BankProfile{BalanceColumn: "saldo"})
+	if err != nil {
+		t.Fatalf("readBankCSV error: %v", err)
+	}
+	if len(bals) != 2 || bals[0].Count != 2 || bals[1].Count != 2 {
+		t.Fatalf("expected rows grouped per date, got %+v", bals)
+	}
+}
+
+func TestLoadBanksWithBalances(t *testing.T) {
+	sources := []BankSource{
+		NewMT940File("testdata/mt940_bankB.sta", "bankB"),
+		NewBankCSVFile("../../testdata/bankA.csv", "bankA", BankProfile{}),
+		NewCamt053File("testdata/camt053_bankA.xml", "bankA"),
+	}
+	got, bals, err := LoadBanksWithBalances(sources, 2)
+	if err != nil {
+		t.Fatalf("LoadBanksWithBalances error: %v", err)
+	}
+	if len(got) != 2 || len(bals) != 3 {
+		t.Fatalf("unexpected result: %d banks, %d balances", len(got), len(bals))
+	}
+	if bals[0].BankName != "bankB" || bals[2].BankName != "bankA" {
+		t.Fatalf("balances not in source order: %+v", bals)
+	}
+}
@@ -0,0 +1,301 @@
+package loader
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// LoadCamt053 membaca bank statement ISO 20022 camt.053 (XML) dari file
+// (boleh .gz, atau .zip berisi beberapa file .xml).
+func LoadCamt053(path, bankName string) ([]BankStatement, error) {
+	return NewCamt053File(path, bankName).BankStatements()
+}
+
+// ReadCamt053 membaca bank statement camt.053 dari reader.
+func ReadCamt053(r io.Reader, bankName string) ([]BankStatement, error) {
+	return NewCamt053Reader(r, bankName).BankStatements()
+}
+
+// NewCamt053File membuat BankSource camt.053 dari file.
+func NewCamt053File(path, bankName string) BankSource {
+	return camt053{open: fileOpener(path, camt053Exts), source: path, name: bankName}
+}
+
+// NewCamt053Reader membuat BankSource camt.053 dari reader. Reader hanya
+// dapat dibaca sekali.
+func NewCamt053Reader(r io.Reader, bankName string) BankSource {
+	return camt053{open: readerOpener(r, camt053Exts), name: bankName}
+}
+
+type camt053 struct {
+	open   opener
+	source string
+	name   string
+}
+
+func (c camt053) BankName() string { return c.name }
+
+func (c camt053) BankStatements() ([]BankStatement, error) {
+	out, _, err := c.BankStatementsWithBalances()
+	return out, err
+}
+
+func (c camt053) BankStatementsWithBalances() ([]BankStatement, []StatementBalance, error) {
+	var out []BankStatement
+	var bals []StatementBalance
+	err := c.open(func(_ string, r io.Reader) error {
+		bs, bl, err := readCamt053(r, c.name)
+		bl = withSource(bs, bl, c.name, c.source)
+		out = append(out, bs...)
+		bals = append(bals, bl...)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, bals, nil
+}
+
+// Struktur XML camt.053 (hanya elemen yang dipakai). Tag tanpa namespace
+// sehingga versi skema 001.02 s.d. 001.08 dapat dibaca.
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID       string        `xml:"Id"`
+	IBAN     string        `xml:"Acct>Id>IBAN"`
+	OtherID  string        `xml:"Acct>Id>Othr>Id"`
+	Balances []camtBalance `xml:"Bal"`
+	Entries  []camtEntry   `xml:"Ntry"`
+}
+
+// camtBalance adalah satu <Bal>; yang dipakai hanya saldo awal (OPBD/PRCD)
+// dan saldo akhir (CLBD).
+type camtBalance struct {
+	Type      string   `xml:"Tp>CdOrPrtry>Cd"`
+	Amount    string   `xml:"Amt"`
+	CdtDbtInd string   `xml:"CdtDbtInd"`
+	Date      camtDate `xml:"Dt"`
+}
+
+func (b camtBalance) value() (int64, error) {
+	amt, err := parseDecimal(b.Amount, '.')
+	if err != nil {
+		return 0, fmt.Errorf("invalid balance amount %q: %w", b.Amount, err)
+	}
+	switch strings.TrimSpace(b.CdtDbtInd) {
+	case "CRDT":
+		return amt, nil
+	case "DBIT":
+		return -amt, nil
+	}
+	return 0, fmt.Errorf("invalid balance CdtDbtInd %q", b.CdtDbtInd)
+}
+
+type camtEntry struct {
+	NtryRef      string          `xml:"NtryRef"`
+	Amount       string          `xml:"Amt"`
+	CdtDbtInd    string          `xml:"CdtDbtInd"`
+	Status       camtStatus      `xml:"Sts"`
+	BookingDate  camtDate        `xml:"BookgDt"`
+	ValueDate    camtDate        `xml:"ValDt"`
+	AcctSvcrRef  string          `xml:"AcctSvcrRef"`
+	AddtlNtryInf string          `xml:"AddtlNtryInf"`
+	Details      []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camtStatus menampung <Sts>BOOK</Sts> (001.02) maupun <Sts><Cd>BOOK</Cd></Sts> (001.08).
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+func (s camtStatus) value() string {
+	if s.Code != "" {
+		return strings.TrimSpace(s.Code)
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+type camtDate struct {
+	Date     string `xml:"Dt"`
+	DateTime string `xml:"DtTm"`
+}
+
+// parse mengambil tanggal kalender; untuk DtTm dipakai tanggal sebagaimana tertulis.
+func (d camtDate) parse() (time.Time, error) {
+	s := strings.TrimSpace(d.Date)
+	if s == "" {
+		s = strings.TrimSpace(d.DateTime)
+		if len(s) > len("2006-01-02") {
+			s = s[:len("2006-01-02")]
+		}
+	}
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+type camtTxDetails struct {
+	EndToEndID   string   `xml:"Refs>EndToEndId"`
+	AcctSvcrRef  string   `xml:"Refs>AcctSvcrRef"`
+	Unstructured []string `xml:"RmtInf>Ustrd"`
+}
+
+// readCamt053 membaca satu dokumen camt.053. Hanya entri berstatus BOOK
+// (atau tanpa status) yang diambil; satu <Ntry> menjadi satu BankStatement.
+// Statement yang memiliki saldo awal dan akhir menghasilkan satu StatementBalance.
+func readCamt053(r io.Reader, bankName string) ([]BankStatement, []StatementBalance, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("invalid camt.053 xml: %w", err)
+	}
+	if len(doc.Statements) == 0 {
+		return nil, nil, fmt.Errorf("invalid camt.053: no Stmt element")
+	}
+	var out []BankStatement
+	var bals []StatementBalance
+	for _, st := range doc.Statements {
+		account := st.IBAN
+		if account == "" {
+			account = st.OtherID
+		}
+		var total int64
+		count := 0
+		for i, e := range st.Entries {
+			if sts := e.Status.value(); sts != "" && !strings.EqualFold(sts, "BOOK") {
+				continue
+			}
+			b, err := e.statement(bankName, account)
+			if err != nil {
+				return nil, nil, fmt.Errorf("statement %s entry %d: %w", st.ID, i+1, err)
+			}
+			if b.UniqueIdentifier == "" {
+				b.UniqueIdentifier = fmt.Sprintf("%s-%d", st.ID, i+1)
+			}
+			out = append(out, b)
+			total += b.Amount
+			count++
+		}
+		bal, ok, err := st.balance(account, total, count)
+		if err != nil {
+			return nil, nil, fmt.Errorf("statement %s: %w", st.ID, err)
+		}
+		if ok {
+			bals = append(bals, bal)
+		}
+	}
+	return out, bals, nil
+}
+
+// balance menyusun StatementBalance dari saldo OPBD/PRCD dan CLBD; ok false
+// jika salah satunya tidak ada.
+func (st camtStatement) balance(account string, total int64, count int) (StatementBalance, bool, error) {
+	var opening, closing *camtBalance
+	for i := range st.Balances {
+		switch strings.TrimSpace(st.Balances[i].Type) {
+		case "OPBD", "PRCD":
+			if opening == nil {
+				opening = &st.Balances[i]
+			}
+		case "CLBD":
+			closing = &st.Balances[i]
+		}
+	}
+	if opening == nil || closing == nil {
+		return StatementBalance{}, false, nil
+	}
+	openAmt, err := opening.value()
+	if err != nil {
+		return StatementBalance{}, false, err
+	}
+	closeAmt, err := closing.value()
+	if err != nil {
+		return StatementBalance{}, false, err
+	}
+	date, err := closing.Date.parse()
+	if err != nil {
+		return StatementBalance{}, false, fmt.Errorf("invalid closing balance date: %w", err)
+	}
+	return StatementBalance{
+		Statement: st.ID,
+		Account:   account,
+		Date:      date,
+		Opening:   openAmt,
+		Closing:   closeAmt,
+		Total:     total,
+		Count:     count,
+	}, true, nil
+}
+
+func (e camtEntry) statement(bankName, account string) (BankStatement, error) {
+	amt, err := parseDecimal(e.Amount, '.')
+	if err != nil {
+		return BankStatement{}, fmt.Errorf("invalid amount %q: %w", e.Amount, err)
+	}
+	switch strings.TrimSpace(e.CdtDbtInd) {
+	case "CRDT":
+	case "DBIT":
+		amt = -amt
+	default:
+		return BankStatement{}, fmt.Errorf("invalid CdtDbtInd %q", e.CdtDbtInd)
+	}
+	booked, err := e.BookingDate.parse()
+	if err != nil {
+		return BankStatement{}, fmt.Errorf("invalid booking date: %w", err)
+	}
+	value, err := e.ValueDate.parse()
+	if err != nil {
+		return BankStatement{}, fmt.Errorf("invalid value date: %w", err)
+	}
+	if booked.IsZero() {
+		booked = value
+	}
+	if booked.IsZero() {
+		return BankStatement{}, fmt.Errorf("missing booking and value date")
+	}
+
+	var ref, txRef string
+	var desc []string
+	for _, d := range e.Details {
+		if ref == "" && d.EndToEndID != "" && d.EndToEndID != "NOTPROVIDED" {
+			ref = strings.TrimSpace(d.EndToEndID)
+		}
+		if txRef == "" {
+			txRef = strings.TrimSpace(d.AcctSvcrRef)
+		}
+		for _, u := range d.Unstructured {
+			if u = strings.TrimSpace(u); u != "" {
+				desc = append(desc, u)
+			}
+		}
+	}
+	if len(desc) == 0 && strings.TrimSpace(e.AddtlNtryInf) != "" {
+		desc = append(desc, strings.TrimSpace(e.AddtlNtryInf))
+	}
+
+	return BankStatement{
+		UniqueIdentifier: firstNonEmpty(e.AcctSvcrRef, e.NtryRef, txRef, ref),
+		Amount:           amt,
+		Date:             booked,
+		BankName:         bankName,
+		ValueDate:        value,
+		Account:          account,
+		Reference:        ref,
+		Description:      strings.Join(desc, " "),
+	}, nil
+}
+
+func firstNonEmpty(vs ...string) string {
+	for _, v := range vs {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
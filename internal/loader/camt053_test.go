@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadCamt053(t *testing.T) {
+	got, err := LoadCamt053("testdata/camt053_bankA.xml", "bankA")
+	if err != nil {
+		t.Fatalf("LoadCamt053 error: %v", err)
+	}
+	if len(got) != 3 {
+		t.Fatalf("expected 3 booked entries, got %d: %+v", len(got), got)
+	}
+
+	first := got[0]
+	if first.UniqueIdentifier != "BA-7781" || first.Amount != 250000 || first.BankName != "bankA" ||
+		first.Account != "ID12BANK0001234567" || first.Reference != "TRX-1001" ||
+		first.Description != "Angsuran pinjaman L-0042 Siti Aminah" ||
+		!first.Date.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected first entry: %+v", first)
+	}
+
+	second := got[1]
+	if second.UniqueIdentifier != "2" || second.Amount != -125000 || second.Reference != "" ||
+		second.Description != "Pencairan pinjaman L-0050" ||
+		!second.Date.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) ||
+		!second.ValueDate.Equal(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected second entry: %+v", second)
+	}
+
+	third := got[2]
+	if third.UniqueIdentifier != "STMT-0601-B-1" || third.Account != "0009876543" || third.Amount != 42000 {
+		t.Fatalf("unexpected third entry: %+v", third)
+	}
+}
+
+func TestReadCamt053_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"not xml":      "unique_identifier,amount,date\n",
+		"no statement": "<Document><BkToCstmrStmt></BkToCstmrStmt></Document>",
+		"fraction": `<Document><BkToCstmrStmt><Stmt><Id>S</Id><Ntry><Amt>10.50</Amt><CdtDbtInd>CRDT</CdtDbtInd>` +
+			`<BookgDt><Dt>2025-06-01</Dt></BookgDt></Ntry></Stmt></BkToCstmrStmt></Document>`,
+		"indicator": `<Document><BkToCstmrStmt><Stmt><Id>S</Id><Ntry><Amt>10</Amt><CdtDbtInd>X</CdtDbtInd>` +
+			`<BookgDt><Dt>2025-06-01</Dt></BookgDt></Ntry></Stmt></BkToCstmrStmt></Document>`,
+		"no date": `<Document><BkToCstmrStmt><Stmt><Id>S</Id><Ntry><Amt>10</Amt><CdtDbtInd>CRDT</CdtDbtInd>` +
+			`</Ntry></Stmt></BkToCstmrStmt></Document>`,
+	}
+	for name, content := range cases {
+		if _, err := ReadCamt053(strings.NewReader(content), "bankA"); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
+
+func TestBankFileSource_Format(t *testing.T) {
+	files := []BankFile{
+		{Name: "bankA", Path: "testdata/camt053_bankA.xml"},
+		{Name: "bankA", Path: "../../testdata/bankA.csv", Format: FormatCSV},
+	}
+	got, err := LoadBankFiles(files, 0)
+	if err != nil {
+		t.Fatalf("LoadBankFiles error: %v", err)
+	}
+	if len(got["bankA"]) != 7 {
+		t.Fatalf("expected 7 statements, got %d", len(got["bankA"]))
+	}
+	if _, err := (BankFile{Path: "x.csv", Format: "ofx"}).Source(); err == nil {
+		t.Fatalf("expected error for unknown format")
+	}
+}
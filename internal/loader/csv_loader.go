@@ -1,10 +1,9 @@
 package loader
 
 import (
-    "encoding/csv"
     "fmt"
     "io"
-    "os"
+    "sort"
     "strconv"
     "time"
 
@@ -14,17 +13,42 @@ import (
 // LoadSystemCSV membaca CSV transaksi sistem.
 // Format header: trxID,amount,type,transactionTime
 func LoadSystemCSV(path string) ([]model.SystemTransaction, error) {
-    f, err := os.Open(path)
+    return LoadSystemCSVWithProfile(path, SystemProfile{})
+}
+
+// LoadSystemCSVWithProfile membaca CSV transaksi sistem dengan pemetaan kolom dari profile.
+// File .gz dan .zip (satu CSV per entri) didekompresi otomatis.
+func LoadSystemCSVWithProfile(path string, p SystemProfile) ([]model.SystemTransaction, error) {
+    return NewSystemCSVFile(path, p).SystemTransactions()
+}
+
+// ReadSystemCSV membaca CSV transaksi sistem dari reader (mis. upload HTTP atau stdin).
+// Konten gzip/zip dideteksi dari magic bytes.
+func ReadSystemCSV(r io.Reader, p SystemProfile) ([]model.SystemTransaction, error) {
+    return NewSystemCSVReader(r, p).SystemTransactions()
+}
+
+// readSystemCSV membaca satu stream CSV transaksi sistem.
+func readSystemCSV(in io.Reader, p SystemProfile) ([]model.SystemTransaction, error) {
+    router, err := compileAccountRules(p.AccountRules)
+    if err != nil {
+        return nil, err
+    }
+    types, err := compileTypes(p.Types, p.UnknownTypes)
+    if err != nil {
+        return nil, err
+    }
+    times, err := p.timeParser()
     if err != nil {
         return nil, err
     }
-    defer f.Close()
-
-    r := csv.NewReader(f)
-    r.TrimLeadingSpace = true
-
     // baca header
-    if _, err := r.Read(); err != nil {
+    r, header, err := p.open(in)
+    if err != nil {
+        return nil, err
+    }
+    cols, err := p.columns(header)
+    if err != nil {
         return nil, err
     }
 
@@ -37,22 +61,32 @@ func LoadSystemCSV(path string) ([]model.SystemTransaction, error) {
         if err != nil {
             return nil, err
         }
-        if len(rec) < 4 {
+        if len(rec) <= cols.max() {
             return nil, fmt.Errorf("invalid system csv row: %v", rec)
         }
-        amt, err := parseAmount(rec[1])
+        amt, err := p.parseAmount(rec[cols.amount])
         if err != nil {
-            return nil, fmt.Errorf("invalid amount %q: %w", rec[1], err)
+            return nil, fmt.Errorf("invalid amount %q: %w", rec[cols.amount], err)
         }
-        t, err := time.Parse(time.RFC3339, rec[3])
+        t, err := times.parse(rec[cols.time])
         if err != nil {
-            return nil, fmt.Errorf("invalid transactionTime %q: %w", rec[3], err)
+            return nil, fmt.Errorf("invalid transactionTime %q: %w", rec[cols.time], err)
+        }
+        typ, err := types.canonical(rec[cols.typ])
+        if err != nil {
+            return nil, fmt.Errorf("transaction %s: %w", rec[cols.id], err)
+        }
+        account := optionalField(rec, cols.account)
+        if account == "" {
+            account = router.account(rec[cols.id], typ)
         }
         out = append(out, model.SystemTransaction{
-            TrxID:           rec[0],
+            TrxID:           rec[cols.id],
             Amount:          amt,
-            Type:            rec[2],
+            Type:            typ,
             TransactionTime: t,
+            Account:         account,
+            Description:     optionalField(rec, cols.description),
         })
     }
     return out, nil
@@ -61,55 +95,154 @@ func LoadSystemCSV(path string) ([]model.SystemTransaction, error) {
 // LoadBankCSV membaca CSV bank statement.
 // Format header: unique_identifier,amount,date
 func LoadBankCSV(path string, bankName string) ([]BankStatement, error) {
-    f, err := os.Open(path)
-    if err != nil {
-        return nil, err
-    }
-    defer f.Close()
+    return LoadBankCSVWithProfile(path, bankName, BankProfile{})
+}
 
-    r := csv.NewReader(f)
-    r.TrimLeadingSpace = true
+// LoadBankCSVWithProfile membaca CSV bank statement dengan pemetaan kolom dari profile.
+// File .gz dan .zip (satu CSV per entri) didekompresi otomatis.
+func LoadBankCSVWithProfile(path string, bankName string, p BankProfile) ([]BankStatement, error) {
+    return NewBankCSVFile(path, bankName, p).BankStatements()
+}
 
-    if _, err := r.Read(); err != nil {
-        return nil, err
+// ReadBankCSV membaca CSV bank statement dari reader. Konten gzip/zip
+// dideteksi dari magic bytes.
+func ReadBankCSV(r io.Reader, bankName string, p BankProfile) ([]BankStatement, error) {
+    return NewBankCSVReader(r, bankName, p).BankStatements()
+}
+
+// readBankCSV membaca satu stream CSV bank statement. Jika profile memiliki
+// BalanceColumn (saldo berjalan setelah setiap baris), saldo awal/akhir per
+// tanggal ikut dikembalikan.
+func readBankCSV(in io.Reader, bankName string, p BankProfile) ([]BankStatement, []StatementBalance, error) {
+    if err := p.Validate(); err != nil {
+        return nil, nil, err
+    }
+    r, header, err := p.open(in)
+    if err != nil {
+        return nil, nil, err
+    }
+    cols, err := p.columns(header)
+    if err != nil {
+        return nil, nil, err
+    }
+    dates, err := p.dateParser()
+    if err != nil {
+        return nil, nil, err
     }
 
     var out []BankStatement
+    var rows []balanceRow
     for {
         rec, err := r.Read()
         if err == io.EOF {
             break
         }
         if err != nil {
-            return nil, err
+            return nil, nil, err
         }
-        if len(rec) < 3 {
-            return nil, fmt.Errorf("invalid bank csv row: %v", rec)
+        if len(rec) <= cols.max() {
+            return nil, nil, fmt.Errorf("invalid bank csv row: %v", rec)
         }
-        amt, err := parseAmount(rec[1])
+        amt, err := p.amount(rec, cols)
         if err != nil {
-            return nil, fmt.Errorf("invalid amount %q: %w", rec[1], err)
+            return nil, nil, err
         }
-        d, err := time.Parse("2006-01-02", rec[2])
+        d, err := dates.date(rec[cols.date])
         if err != nil {
-            return nil, fmt.Errorf("invalid date %q: %w", rec[2], err)
+            return nil, nil, fmt.Errorf("invalid date %q: %w", rec[cols.date], err)
         }
         out = append(out, BankStatement{
-            UniqueIdentifier: rec[0],
+            UniqueIdentifier: rec[cols.id],
             Amount:           amt,
             Date:             d,
             BankName:         bankName,
+            Reference:        optionalField(rec, cols.reference),
+            Description:      optionalField(rec, cols.description),
         })
+        if cols.balance >= 0 {
+            bal, err := p.parseAmount(rec[cols.balance])
+            if err != nil {
+                return nil, nil, fmt.Errorf("invalid balance %q: %w", rec[cols.balance], err)
+            }
+            rows = append(rows, balanceRow{date: d, amount: amt, balance: bal})
+        }
     }
-    return out, nil
+    return out, runningBalances(rows), nil
+}
+
+// balanceRow adalah amount dan saldo berjalan satu baris statement.
+type balanceRow struct {
+    date    time.Time
+    amount  int64
+    balance int64
+}
+
+// runningBalances menurunkan saldo awal/akhir per tanggal dari saldo berjalan
+// setiap baris, tanpa bergantung pada urutan baris di file. Di dalam satu
+// tanggal, saldo awal (saldo - amount) diambil dari awal rantai saldo dan
+// saldo akhir dari ujungnya; bila rantai tidak tunggal (mis. saldo tidak
+// konsisten), baris pertama dan terakhir di file yang dipakai. Hasil urut
+// tanggal.
+func runningBalances(rows []balanceRow) []StatementBalance {
+    var dates []time.Time
+    byDate := map[time.Time][]balanceRow{}
+    for _, r := range rows {
+        if _, ok := byDate[r.date]; !ok {
+            dates = append(dates, r.date)
+        }
+        byDate[r.date] = append(byDate[r.date], r)
+    }
+    sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+    var out []StatementBalance
+    for _, d := range dates {
+        day := byDate[d]
+        first, last := chainEnds(day)
+        b := StatementBalance{Date: d, Opening: first.balance - first.amount, Closing: last.balance, Count: len(day)}
+        for _, r := range day {
+            b.Total += r.amount
+        }
+        out = append(out, b)
+    }
+    return out
+}
+
+// chainEnds mencari baris pertama dan terakhir rantai saldo satu tanggal:
+// baris pertama adalah satu-satunya yang saldo sebelumnya bukan saldo baris
+// lain, baris terakhir satu-satunya yang saldonya bukan saldo sebelum baris lain.
+func chainEnds(day []balanceRow) (balanceRow, balanceRow) {
+    first, last := day[0], day[len(day)-1]
+    balances, prevs := map[int64]int{}, map[int64]int{}
+    for _, r := range day {
+        balances[r.balance]++
+        prevs[r.balance-r.amount]++
+    }
+    var starts, ends []balanceRow
+    for _, r := range day {
+        if balances[r.balance-r.amount] == 0 {
+            starts = append(starts, r)
+        }
+        if prevs[r.balance] == 0 {
+            ends = append(ends, r)
+        }
+    }
+    if len(starts) == 1 && len(ends) == 1 {
+        return starts[0], ends[0]
+    }
+    return first, last
 }
 
 // BankStatement adalah versi loader untuk menyertakan nama bank.
 type BankStatement struct {
     UniqueIdentifier string
     Amount           int64
-    Date             time.Time
+    Date             time.Time // tanggal booking
     BankName         string
+    ValueDate        time.Time // tanggal valuta; zero jika sumber tidak menyediakan
+    Account          string    // nomor rekening/IBAN; kosong jika tidak tersedia
+    Reference        string    // referensi transaksi, mis. end-to-end ID
+    Description      string    // narasi/remittance info
+    Source           string    // path file asal; kosong untuk sumber reader
 }
 
 func parseAmount(s string) (int64, error) {
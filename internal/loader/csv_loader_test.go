@@ -129,4 +129,44 @@ func TestParseAmount(t *testing.T) {
             }
         }
     }
-}
\ No newline at end of file
+}
+
+func TestLoadBankCSVWithProfile(t *testing.T) {
+    dir := t.TempDir()
+    content := "tanggal,keterangan,nominal,ref\n" +
+        "01/06/2025,transfer masuk,250000,BA-1\n" +
+        "03/06/2025,biaya,-5000,BA-2\n"
+    p := writeTempFile(t, dir, "bank_profile.csv", content)
+
+    prof := BankProfile{IDColumn: "REF", AmountColumn: "nominal", DateColumn: "tanggal", DateLayout: "02/01/2006"}
+    got, err := LoadBankCSVWithProfile(p, "bankA", prof)
+    if err != nil {
+        t.Fatalf("LoadBankCSVWithProfile error: %v", err)
+    }
+    if len(got) != 2 {
+        t.Fatalf("len(got)=%d", len(got))
+    }
+    if got[1].UniqueIdentifier != "BA-2" || got[1].Amount != -5000 || !got[1].Date.Equal(time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)) {
+        t.Fatalf("unexpected second row: %+v", got[1])
+    }
+
+    if _, err := LoadBankCSVWithProfile(p, "bankA", BankProfile{AmountColumn: "amount"}); err == nil {
+        t.Fatalf("expected error for missing column")
+    }
+}
+
+func TestLoadSystemCSVWithProfile(t *testing.T) {
+    dir := t.TempDir()
+    content := "time,type,id,amount\n" +
+        "2025-06-01T12:34:56Z,DEBIT,TRX-1,125000\n"
+    p := writeTempFile(t, dir, "system_profile.csv", content)
+
+    prof := SystemProfile{IDColumn: "id", AmountColumn: "amount", TypeColumn: "type", TimeColumn: "time"}
+    got, err := LoadSystemCSVWithProfile(p, prof)
+    if err != nil {
+        t.Fatalf("LoadSystemCSVWithProfile error: %v", err)
+    }
+    if len(got) != 1 || got[0].TrxID != "TRX-1" || got[0].Amount != 125000 || got[0].Type != "DEBIT" {
+        t.Fatalf("unexpected rows: %+v", got)
+    }
+}
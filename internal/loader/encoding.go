@@ -0,0 +1,145 @@
+package loader
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// windows1252High memetakan byte 0x80-0x9F Windows-1252 ke Unicode;
+// byte lain identik dengan ISO-8859-1.
+var windows1252High = [32]rune{
+	0x20AC, 0x0081, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0x008D, 0x017D, 0x008F,
+	0x0090, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0x009D, 0x017E, 0x0178,
+}
+
+// decodeText mengubah stream ber-encoding tertentu menjadi UTF-8. BOM
+// (UTF-8 atau UTF-16) selalu dibuang dan menentukan encoding yang dipakai.
+func decodeText(r io.Reader, encoding string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(utf8BOM))
+	switch {
+	case bytes.HasPrefix(head, utf8BOM):
+		br.Discard(len(utf8BOM))
+		return br, nil
+	case bytes.HasPrefix(head, utf16LEBOM):
+		br.Discard(len(utf16LEBOM))
+		return decodeUTF16(br, false)
+	case bytes.HasPrefix(head, utf16BEBOM):
+		br.Discard(len(utf16BEBOM))
+		return decodeUTF16(br, true)
+	}
+	switch normalizeEncoding(encoding) {
+	case "utf16le":
+		return decodeUTF16(br, false)
+	case "utf16be":
+		return decodeUTF16(br, true)
+	}
+	table, err := singleByteTable(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if table == nil {
+		return br, nil
+	}
+	return &singleByteReader{r: br, table: table}, nil
+}
+
+func normalizeEncoding(e string) string {
+	return strings.NewReplacer("-", "", "_", "").Replace(strings.ToLower(strings.TrimSpace(e)))
+}
+
+func isUTF16(e string) bool {
+	n := normalizeEncoding(e)
+	return n == "utf16le" || n == "utf16be"
+}
+
+// singleByteTable mengembalikan tabel decoding untuk encoding satu byte,
+// atau nil untuk UTF-8.
+func singleByteTable(encoding string) (*[256]rune, error) {
+	var t [256]rune
+	for i := range t {
+		t[i] = rune(i)
+	}
+	switch normalizeEncoding(encoding) {
+	case "", "utf8":
+		return nil, nil
+	case "iso88591", "latin1":
+		return &t, nil
+	case "windows1252", "cp1252":
+		for i, r := range windows1252High {
+			t[0x80+i] = r
+		}
+		return &t, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding %q", encoding)
+	}
+}
+
+// singleByteReader men-decode encoding satu byte menjadi UTF-8 secara streaming.
+type singleByteReader struct {
+	r       *bufio.Reader
+	table   *[256]rune
+	pending []byte
+}
+
+func (d *singleByteReader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			c := copy(p[n:], d.pending)
+			d.pending = d.pending[c:]
+			n += c
+			continue
+		}
+		b, err := d.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		r := d.table[b]
+		if r < utf8.RuneSelf {
+			p[n] = byte(r)
+			n++
+			continue
+		}
+		var buf [utf8.UTFMax]byte
+		w := utf8.EncodeRune(buf[:], r)
+		d.pending = append(d.pending[:0], buf[:w]...)
+	}
+	return n, nil
+}
+
+// decodeUTF16 membaca seluruh stream UTF-16 dan mengembalikannya sebagai UTF-8.
+func decodeUTF16(r io.Reader, bigEndian bool) (io.Reader, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw)%2 != 0 {
+		return nil, fmt.Errorf("invalid utf-16 input: odd length")
+	}
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(raw[2*i])<<8 | uint16(raw[2*i+1])
+		} else {
+			units[i] = uint16(raw[2*i+1])<<8 | uint16(raw[2*i])
+		}
+	}
+	return strings.NewReader(string(utf16.Decode(units))), nil
+}
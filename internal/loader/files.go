@@ -0,0 +1,192 @@
+package loader
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Format file bank statement yang didukung.
+const (
+	FormatCSV     = "csv"
+	FormatCamt053 = "camt053"
+	FormatMT940   = "mt940"
+)
+
+// BankFile adalah satu file bank statement beserta nama bank pemiliknya.
+// Format kosong berarti dideteksi dari ekstensi file (lihat DetectFormat).
+type BankFile struct {
+	Name    string
+	Path    string
+	Format  string
+	Profile BankProfile // hanya untuk FormatCSV
+
+	// EntryPattern (opsional, hanya CSV) menamai bank per entri zip dari
+	// nama entrinya, mis. "{bank}.csv", sehingga satu arsip dapat berisi
+	// statement beberapa bank. Entri yang tidak cocok tetap memakai Name.
+	// EntryProfiles memberi profile per nama bank hasil pola; default Profile.
+	EntryPattern  *NamePattern
+	EntryProfiles map[string]BankProfile
+}
+
+// entryNaming menamai bank dan memilih profile untuk entri zip.
+type entryNaming struct {
+	pattern  *NamePattern
+	profiles map[string]BankProfile
+}
+
+func (n entryNaming) bank(entry, name string, p BankProfile) (string, BankProfile) {
+	if n.pattern == nil {
+		return name, p
+	}
+	bank, ok := n.pattern.BankName(entry)
+	if !ok {
+		return name, p
+	}
+	if bp, ok := n.profiles[bank]; ok {
+		p = bp
+	}
+	return bank, p
+}
+
+// DefaultNamePattern mengambil nama bank dari nama file tanpa ekstensi.
+const DefaultNamePattern = "{bank}.*"
+
+// NamePattern memetakan nama file ke nama bank, mis. "{bank}_{date}.csv".
+// Placeholder: {bank} (wajib), {date} (YYYY-MM-DD atau YYYYMMDD),
+// serta wildcard * dan ?.
+type NamePattern struct {
+	raw string
+	re  *regexp.Regexp
+}
+
+// CompileNamePattern mengompilasi pola nama file; pola kosong berarti DefaultNamePattern.
+func CompileNamePattern(pattern string) (*NamePattern, error) {
+	if pattern == "" {
+		pattern = DefaultNamePattern
+	}
+	if !strings.Contains(pattern, "{bank}") {
+		return nil, fmt.Errorf("name pattern %q must contain {bank}", pattern)
+	}
+	var b strings.Builder
+	b.WriteString("^")
+	for rest := pattern; rest != ""; {
+		switch {
+		case strings.HasPrefix(rest, "{bank}"):
+			b.WriteString(`(?P<bank>[^/\\]+?)`)
+			rest = rest[len("{bank}"):]
+		case strings.HasPrefix(rest, "{date}"):
+			b.WriteString(`(?P<date>[0-9]{4}-?[0-9]{2}-?[0-9]{2})`)
+			rest = rest[len("{date}"):]
+		case rest[0] == '*':
+			b.WriteString(`.*`)
+			rest = rest[1:]
+		case rest[0] == '?':
+			b.WriteString(`.`)
+			rest = rest[1:]
+		default:
+			b.WriteString(regexp.QuoteMeta(rest[:1]))
+			rest = rest[1:]
+		}
+	}
+	b.WriteString("$")
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern %q: %w", pattern, err)
+	}
+	return &NamePattern{raw: pattern, re: re}, nil
+}
+
+// BankName mengembalikan nama bank dari nama file (basename) jika cocok dengan pola.
+func (p *NamePattern) BankName(path string) (string, bool) {
+	m := p.re.FindStringSubmatch(filepath.Base(path))
+	if m == nil {
+		return "", false
+	}
+	return m[p.re.SubexpIndex("bank")], true
+}
+
+func (p *NamePattern) String() string { return p.raw }
+
+// FindBankFiles mencari file yang cocok dengan glob lalu menamai bank
+// memakai pola nama file. File yang tidak cocok dengan pola dilewati.
+// Hasil diurutkan berdasarkan path.
+func FindBankFiles(glob string, pattern *NamePattern) ([]BankFile, error) {
+	paths, err := filepath.Glob(glob)
+	if err != nil {
+		return nil, fmt.Errorf("invalid glob %q: %w", glob, err)
+	}
+	var out []BankFile
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			return nil, err
+		}
+		if info.IsDir() {
+			continue
+		}
+		name, ok := pattern.BankName(p)
+		if !ok {
+			continue
+		}
+		out = append(out, BankFile{Name: name, Path: p})
+	}
+	return out, nil
+}
+
+// DetectFormat menebak format dari ekstensi file (mengabaikan .gz):
+// .xml berarti camt.053, .sta/.mt940/.940 berarti MT940, selain itu CSV.
+func DetectFormat(path string) string {
+	p := strings.TrimSuffix(strings.ToLower(path), ".gz")
+	switch filepath.Ext(p) {
+	case ".xml":
+		return FormatCamt053
+	case ".sta", ".mt940", ".940":
+		return FormatMT940
+	default:
+		return FormatCSV
+	}
+}
+
+// Source mengembalikan BankSource sesuai format file ini.
+func (f BankFile) Source() (BankSource, error) {
+	format := f.Format
+	if format == "" {
+		format = DetectFormat(f.Path)
+	}
+	switch format {
+	case FormatCSV:
+		src := NewBankCSVFile(f.Path, f.Name, f.Profile).(bankCSV)
+		src.entries = entryNaming{pattern: f.EntryPattern, profiles: f.EntryProfiles}
+		return src, nil
+	case FormatCamt053:
+		return NewCamt053File(f.Path, f.Name), nil
+	case FormatMT940:
+		return NewMT940File(f.Path, f.Name), nil
+	default:
+		return nil, fmt.Errorf("unknown bank file format %q", f.Format)
+	}
+}
+
+// LoadBankFiles memuat file-file bank secara konkuren (lihat LoadBanks) dan
+// menggabungkan statement per nama bank.
+func LoadBankFiles(files []BankFile, workers int) (map[string][]BankStatement, error) {
+	out, _, err := LoadBankFilesWithBalances(files, workers)
+	return out, err
+}
+
+// LoadBankFilesWithBalances seperti LoadBankFiles tetapi juga mengembalikan
+// saldo statement (lihat LoadBanksWithBalances).
+func LoadBankFilesWithBalances(files []BankFile, workers int) (map[string][]BankStatement, []StatementBalance, error) {
+	sources := make([]BankSource, len(files))
+	for i, f := range files {
+		src, err := f.Source()
+		if err != nil {
+			return nil, nil, fmt.Errorf("bank file %s: %w", f.Path, err)
+		}
+		sources[i] = src
+	}
+	return LoadBanksWithBalances(sources, workers)
+}
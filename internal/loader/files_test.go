@@ -0,0 +1,77 @@
+package loader
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNamePattern(t *testing.T) {
+	cases := []struct {
+		pattern string
+		file    string
+		bank    string
+		ok      bool
+	}{
+		{"{bank}_{date}.csv", "/in/bankA_2025-06-01.csv", "bankA", true},
+		{"{bank}_{date}.csv", "bank_mandiri_20250601.csv", "bank_mandiri", true},
+		{"{bank}_{date}.csv", "bankA_latest.csv", "", false},
+		{"stmt-{bank}-*.csv", "stmt-bca-0001.csv", "bca", true},
+		{"", "bankB.csv", "bankB", true},
+		{"", "bankB.csv.gz", "bankB", true},
+	}
+	for _, c := range cases {
+		p, err := CompileNamePattern(c.pattern)
+		if err != nil {
+			t.Fatalf("CompileNamePattern(%q) error: %v", c.pattern, err)
+		}
+		bank, ok := p.BankName(c.file)
+		if ok != c.ok || bank != c.bank {
+			t.Fatalf("%q.BankName(%q) => %q,%v", c.pattern, c.file, bank, ok)
+		}
+	}
+	if _, err := CompileNamePattern("{date}.csv"); err == nil {
+		t.Fatalf("expected error for pattern without {bank}")
+	}
+}
+
+func TestFindAndLoadBankFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeTempFile(t, dir, "bankA_2025-06-01.csv", "unique_identifier,amount,date\nBA-1,100,2025-06-01\n")
+	writeTempFile(t, dir, "bankA_2025-06-02.csv", "unique_identifier,amount,date\nBA-2,200,2025-06-02\nBA-3,300,2025-06-02\n")
+	writeTempFile(t, dir, "bankB_2025-06-01.csv", "unique_identifier,amount,date\nBB-1,-50,2025-06-01\n")
+	writeTempFile(t, dir, "README.txt", "not a statement")
+	if err := os.Mkdir(filepath.Join(dir, "archive_2025-05-31.csv"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+
+	p, err := CompileNamePattern("{bank}_{date}.csv")
+	if err != nil {
+		t.Fatalf("CompileNamePattern error: %v", err)
+	}
+	files, err := FindBankFiles(filepath.Join(dir, "*"), p)
+	if err != nil {
+		t.Fatalf("FindBankFiles error: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("expected 3 files, got %+v", files)
+	}
+
+	got, err := LoadBankFiles(files, 2)
+	if err != nil {
+		t.Fatalf("LoadBankFiles error: %v", err)
+	}
+	if len(got["bankA"]) != 3 || len(got["bankB"]) != 1 {
+		t.Fatalf("unexpected banks: %+v", got)
+	}
+	for i, id := range []string{"BA-1", "BA-2", "BA-3"} {
+		if got["bankA"][i].UniqueIdentifier != id || got["bankA"][i].BankName != "bankA" {
+			t.Fatalf("unexpected order/name at %d: %+v", i, got["bankA"][i])
+		}
+	}
+
+	bad := writeTempFile(t, dir, "bankC_2025-06-01.csv", "unique_identifier,amount,date\nBC-1,x,2025-06-01\n")
+	if _, err := LoadBankFiles(append(files, BankFile{Name: "bankC", Path: bad}), 0); err == nil {
+		t.Fatalf("expected error for invalid bank file")
+	}
+}
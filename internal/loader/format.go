@@ -0,0 +1,228 @@
+package loader
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Locale format amount yang didukung CSVFormat.Locale.
+const (
+	LocaleID = "id" // 1.250.000,00
+	LocaleEN = "en" // 1,250,000.00
+)
+
+// CSVFormat mengatur dialek file teks input. Nilai nol berarti CSV standar
+// (koma, kutip ganda, UTF-8) dengan amount bulat yang boleh memakai koma ribuan.
+type CSVFormat struct {
+	Delimiter string `json:"delimiter"`  // satu karakter; default ","; "\t" untuk tab
+	Quote     string `json:"quote"`      // satu karakter ASCII; default `"`
+	Encoding  string `json:"encoding"`   // utf-8 (default), windows-1252, iso-8859-1, utf-16le, utf-16be
+	Locale    string `json:"locale"`     // format amount: id atau en; kosong = default
+	SkipLines int    `json:"skip_lines"` // jumlah baris yang dilewati sebelum header/data
+	// FixedWidth, jika diisi, membaca file sebagai fixed-width; nama kolom
+	// layout dipakai sebagai header sehingga file tidak perlu baris header.
+	FixedWidth []FixedWidthColumn `json:"fixed_width"`
+}
+
+// FixedWidthColumn adalah satu kolom fixed-width; Start dimulai dari 1
+// dan dihitung dalam karakter (setelah decoding).
+type FixedWidthColumn struct {
+	Name  string `json:"name"`
+	Start int    `json:"start"`
+	Width int    `json:"width"`
+}
+
+// recordReader adalah sumber baris record (csv.Reader atau fixedWidthReader).
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// Validate memeriksa konsistensi dialek.
+func (f CSVFormat) Validate() error {
+	delim, err := f.delimiter()
+	if err != nil {
+		return err
+	}
+	q, err := f.quote()
+	if err != nil {
+		return err
+	}
+	if rune(q) == delim {
+		return fmt.Errorf("quote and delimiter must differ")
+	}
+	if _, err := singleByteTable(f.Encoding); err != nil && !isUTF16(f.Encoding) {
+		return err
+	}
+	switch f.Locale {
+	case "", LocaleID, LocaleEN:
+	default:
+		return fmt.Errorf("unknown locale %q", f.Locale)
+	}
+	if f.SkipLines < 0 {
+		return fmt.Errorf("skip_lines must not be negative")
+	}
+	for i, c := range f.FixedWidth {
+		if c.Name == "" || c.Start < 1 || c.Width < 1 {
+			return fmt.Errorf("fixed_width[%d]: name, start >= 1 and width >= 1 are required", i)
+		}
+	}
+	return nil
+}
+
+func (f CSVFormat) delimiter() (rune, error) {
+	switch f.Delimiter {
+	case "":
+		return ',', nil
+	case `\t`, "tab":
+		return '\t', nil
+	}
+	r, size := utf8.DecodeRuneInString(f.Delimiter)
+	if size != len(f.Delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+		return 0, fmt.Errorf("invalid delimiter %q", f.Delimiter)
+	}
+	return r, nil
+}
+
+func (f CSVFormat) quote() (byte, error) {
+	if f.Quote == "" {
+		return '"', nil
+	}
+	if len(f.Quote) != 1 || f.Quote[0] >= utf8.RuneSelf || f.Quote == "\n" || f.Quote == "\r" {
+		return 0, fmt.Errorf("invalid quote %q", f.Quote)
+	}
+	return f.Quote[0], nil
+}
+
+// open menyiapkan recordReader untuk satu stream dan mengembalikan header.
+func (f CSVFormat) open(in io.Reader) (recordReader, []string, error) {
+	if err := f.Validate(); err != nil {
+		return nil, nil, err
+	}
+	decoded, err := decodeText(in, f.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+	br := bufio.NewReader(decoded)
+	for i := 0; i < f.SkipLines; i++ {
+		if _, err := br.ReadString('\n'); err != nil {
+			return nil, nil, fmt.Errorf("skip line %d: %w", i+1, err)
+		}
+	}
+
+	if len(f.FixedWidth) > 0 {
+		header := make([]string, len(f.FixedWidth))
+		for i, c := range f.FixedWidth {
+			header[i] = c.Name
+		}
+		return &fixedWidthReader{r: br, cols: f.FixedWidth}, header, nil
+	}
+
+	delim, _ := f.delimiter()
+	q, _ := f.quote()
+	var src io.Reader = br
+	if q != '"' {
+		// encoding/csv hanya mengenal kutip ganda: tukar karakter kutip dengan
+		// '"' sebelum parsing, lalu kembalikan di setiap field.
+		src = &byteSwapReader{r: br, a: q, b: '"'}
+	}
+	cr := csv.NewReader(src)
+	cr.Comma = delim
+	cr.TrimLeadingSpace = true
+	var rr recordReader = cr
+	if q != '"' {
+		rr = swappedRecords{r: cr, a: q, b: '"'}
+	}
+	header, err := rr.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	return rr, header, nil
+}
+
+// parseAmount mengurai amount sesuai Locale.
+func (f CSVFormat) parseAmount(s string) (int64, error) {
+	return parseLocaleAmount(s, f.Locale)
+}
+
+// fixedWidthReader membaca satu record per baris; baris kosong dilewati.
+type fixedWidthReader struct {
+	r    *bufio.Reader
+	cols []FixedWidthColumn
+}
+
+func (fw *fixedWidthReader) Read() ([]string, error) {
+	for {
+		line, err := fw.r.ReadString('\n')
+		if line == "" && err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if strings.TrimSpace(line) == "" {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		runes := []rune(line)
+		rec := make([]string, len(fw.cols))
+		for i, c := range fw.cols {
+			start := c.Start - 1
+			end := start + c.Width
+			if start >= len(runes) {
+				continue
+			}
+			if end > len(runes) {
+				end = len(runes)
+			}
+			rec[i] = strings.TrimSpace(string(runes[start:end]))
+		}
+		return rec, nil
+	}
+}
+
+// byteSwapReader menukar dua byte ASCII a dan b pada stream.
+type byteSwapReader struct {
+	r    io.Reader
+	a, b byte
+}
+
+func (s *byteSwapReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	for i := 0; i < n; i++ {
+		switch p[i] {
+		case s.a:
+			p[i] = s.b
+		case s.b:
+			p[i] = s.a
+		}
+	}
+	return n, err
+}
+
+// swappedRecords mengembalikan penukaran byteSwapReader pada setiap field.
+type swappedRecords struct {
+	r    recordReader
+	a, b byte
+}
+
+func (s swappedRecords) Read() ([]string, error) {
+	rec, err := s.r.Read()
+	for i, v := range rec {
+		if strings.IndexByte(v, s.a) >= 0 || strings.IndexByte(v, s.b) >= 0 {
+			rec[i] = strings.Map(func(r rune) rune {
+				switch r {
+				case rune(s.a):
+					return rune(s.b)
+				case rune(s.b):
+					return rune(s.a)
+				}
+				return r
+			}, v)
+		}
+	}
+	return rec, err
+}
@@ -0,0 +1,146 @@
+package loader
+
+import (
+	"bytes"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+func TestReadBankCSV_SemicolonLocaleWindows1252(t *testing.T) {
+	// "Penerimaan é" dan "€" ditulis dalam Windows-1252 (0xE9, 0x80).
+	content := []byte("Laporan Mutasi Rekening\n" +
+		"ref;tanggal;keterangan;nominal\n" +
+		"'BA-\xe91';01/06/2025;'Penerimaan; angsuran';1.250.000,00\n" +
+		"BA-2;02/06/2025;Biaya \x80;'(5.000)'\n")
+	prof := BankProfile{
+		CSVFormat:    CSVFormat{Delimiter: ";", Quote: "'", Encoding: "windows-1252", Locale: LocaleID, SkipLines: 1},
+		IDColumn:     "ref",
+		AmountColumn: "nominal",
+		DateColumn:   "tanggal",
+		DateLayout:   "02/01/2006",
+	}
+	got, err := ReadBankCSV(bytes.NewReader(content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got)=%d", len(got))
+	}
+	if got[0].UniqueIdentifier != "BA-é1" || got[0].Amount != 1250000 || !got[0].Date.Equal(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected first row: %+v", got[0])
+	}
+	if got[1].UniqueIdentifier != "BA-2" || got[1].Amount != -5000 {
+		t.Fatalf("unexpected second row: %+v", got[1])
+	}
+}
+
+func TestReadBankCSV_BOM(t *testing.T) {
+	utf8Content := append([]byte{0xef, 0xbb, 0xbf}, "unique_identifier\tamount\tdate\nBA-1\t1,250,000.00\t2025-06-01\n"...)
+	prof := BankProfile{CSVFormat: CSVFormat{Delimiter: `\t`, Locale: LocaleEN, Encoding: "windows-1252"}, IDColumn: "unique_identifier"}
+	got, err := ReadBankCSV(bytes.NewReader(utf8Content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV (utf-8 BOM) error: %v", err)
+	}
+	if len(got) != 1 || got[0].UniqueIdentifier != "BA-1" || got[0].Amount != 1250000 {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+
+	units := utf16.Encode([]rune("unique_identifier\tamount\tdate\nBA-2\t-75,000.00\t2025-06-03\n"))
+	utf16Content := []byte{0xff, 0xfe}
+	for _, u := range units {
+		utf16Content = append(utf16Content, byte(u), byte(u>>8))
+	}
+	got, err = ReadBankCSV(bytes.NewReader(utf16Content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV (utf-16 BOM) error: %v", err)
+	}
+	if len(got) != 1 || got[0].UniqueIdentifier != "BA-2" || got[0].Amount != -75000 {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+}
+
+func TestReadBankCSV_FixedWidth(t *testing.T) {
+	content := "TANGGAL   REFERENSI       NOMINAL\n" +
+		"2025-06-01BA-0001       250.000,00\n" +
+		"\n" +
+		"2025-06-02BA-0002     -1.125.000\n"
+	prof := BankProfile{
+		CSVFormat: CSVFormat{
+			Locale:    LocaleID,
+			SkipLines: 1,
+			FixedWidth: []FixedWidthColumn{
+				{Name: "date", Start: 1, Width: 10},
+				{Name: "ref", Start: 11, Width: 10},
+				{Name: "amount", Start: 21, Width: 20},
+			},
+		},
+		IDColumn:     "ref",
+		AmountColumn: "amount",
+		DateColumn:   "date",
+	}
+	got, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("len(got)=%d: %+v", len(got), got)
+	}
+	if got[0].UniqueIdentifier != "BA-0001" || got[0].Amount != 250000 {
+		t.Fatalf("unexpected first row: %+v", got[0])
+	}
+	if got[1].UniqueIdentifier != "BA-0002" || got[1].Amount != -1125000 || !got[1].Date.Equal(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected second row: %+v", got[1])
+	}
+}
+
+func TestCSVFormat_Validate(t *testing.T) {
+	bad := []CSVFormat{
+		{Delimiter: ";;"},
+		{Quote: "«"},
+		{Delimiter: ";", Quote: ";"},
+		{Encoding: "ebcdic"},
+		{Locale: "fr"},
+		{SkipLines: -1},
+		{FixedWidth: []FixedWidthColumn{{Name: "a", Start: 0, Width: 3}}},
+	}
+	for _, f := range bad {
+		if err := f.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", f)
+		}
+	}
+	if err := (CSVFormat{Delimiter: "tab", Encoding: "UTF-16LE", Locale: LocaleID}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestParseLocaleAmount(t *testing.T) {
+	cases := []struct {
+		in     string
+		locale string
+		out    int64
+		ok     bool
+	}{
+		{"1.250.000,00", LocaleID, 1250000, true},
+		{"-1.250.000", LocaleID, -1250000, true},
+		{"(5.000,00)", LocaleID, -5000, true},
+		{"1 250 000", LocaleID, 1250000, true},
+		{"1\u00a0250\u00a0000", LocaleID, 1250000, true},
+		{"1\u00a0250\u00a0000.00", LocaleEN, 1250000, true},
+		{"1.250,50", LocaleID, 0, false},
+		{"1,250,000.00", LocaleEN, 1250000, true},
+		{"(-5,000)", LocaleEN, 0, false},
+		{"1,234", "", 1234, true},
+		{"abc", LocaleID, 0, false},
+	}
+	for _, c := range cases {
+		v, err := parseLocaleAmount(c.in, c.locale)
+		if c.ok {
+			if err != nil || v != c.out {
+				t.Fatalf("parseLocaleAmount(%q,%q) => %v,%v", c.in, c.locale, v, err)
+			}
+		} else if err == nil {
+			t.Fatalf("expected error for %q (%q)", c.in, c.locale)
+		}
+	}
+}
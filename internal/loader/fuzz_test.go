@@ -0,0 +1,142 @@
+package loader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func FuzzParseAmount(f *testing.F) {
+	for _, s := range []string{"250000", "-125,000", "+1,000", "", "1.5", "9223372036854775807", "-9,223,372,036,854,775,808", "１２３"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := parseAmount(s)
+		if err != nil {
+			return
+		}
+		// Hasil harus sama dengan input tanpa koma ribuan.
+		want, werr := strconv.ParseInt(strings.ReplaceAll(s, ",", ""), 10, 64)
+		if werr != nil || v != want {
+			t.Fatalf("parseAmount(%q)=%d, want %d (%v)", s, v, want, werr)
+		}
+		// Format ulang dengan koma ribuan harus menghasilkan nilai yang sama.
+		if again, err := parseAmount(withThousands(v)); err != nil || again != v {
+			t.Fatalf("round trip %d via %q: %d, %v", v, withThousands(v), again, err)
+		}
+	})
+}
+
+func FuzzParseLocaleAmount(f *testing.F) {
+	for _, s := range []string{"1.250.000,00", "(5.000)", "1,250,000.00", "-0,00", "()", "1 250"} {
+		f.Add(s, LocaleID)
+		f.Add(s, LocaleEN)
+	}
+	f.Fuzz(func(t *testing.T, s, locale string) {
+		// Tidak boleh panic untuk input apa pun.
+		_, _ = parseLocaleAmount(s, locale)
+	})
+}
+
+// Properti loader: hasil yang berhasil dibaca, bila ditulis ulang sebagai CSV
+// standar, harus terbaca kembali menjadi data yang sama.
+
+func FuzzReadSystemCSV(f *testing.F) {
+	f.Add("trxID,amount,type,transactionTime\nTRX-1,250000,CREDIT,2025-06-01T10:15:00Z\n")
+	f.Add("trxID,amount,type,transactionTime\nTRX-1,\"1,000\",DEBIT,2025-06-01T10:15:00+07:00\nTRX-2,x,CREDIT,bad\n")
+	f.Add("trxID,amount\n")
+	f.Add("\xef\xbb\xbftrxID,amount,type,transactionTime\n\"unterminated")
+	f.Fuzz(func(t *testing.T, content string) {
+		txs, err := ReadSystemCSV(strings.NewReader(content), SystemProfile{})
+		if err != nil {
+			return
+		}
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"trxID", "amount", "type", "transactionTime"})
+		for _, tx := range txs {
+			w.Write([]string{tx.TrxID, strconv.FormatInt(tx.Amount, 10), tx.Type, tx.TransactionTime.Format(time.RFC3339Nano)})
+		}
+		w.Flush()
+		again, err := ReadSystemCSV(&buf, SystemProfile{})
+		if err != nil {
+			t.Fatalf("re-read failed: %v\n%s", err, buf.String())
+		}
+		if len(again) != len(txs) {
+			t.Fatalf("re-read %d rows, want %d", len(again), len(txs))
+		}
+		for i := range txs {
+			a, b := txs[i], again[i]
+			if a.TrxID != b.TrxID || a.Amount != b.Amount || a.Type != b.Type || !a.TransactionTime.Equal(b.TransactionTime) {
+				t.Fatalf("row %d changed: %+v -> %+v", i, a, b)
+			}
+		}
+	})
+}
+
+func FuzzReadBankCSV(f *testing.F) {
+	f.Add("unique_identifier,amount,date\nBA-1,250000,2025-06-01\n", false)
+	f.Add("unique_identifier,amount,date\nBA-1,-125000,2025-06-01\nBA-2,1,2025-13-01\n", false)
+	f.Add("ref;tanggal;nominal\nBA-1;01/06/2025;1.250.000,00\nBA-2;02/06/2025;(5.000)\n", true)
+	f.Add("ref;tanggal;nominal\n", true)
+	localeProfile := BankProfile{
+		CSVFormat:    CSVFormat{Delimiter: ";", Locale: LocaleID},
+		IDColumn:     "ref",
+		AmountColumn: "nominal",
+		DateColumn:   "tanggal",
+		DateLayout:   "02/01/2006",
+	}
+	f.Fuzz(func(t *testing.T, content string, locale bool) {
+		prof := BankProfile{}
+		if locale {
+			prof = localeProfile
+		}
+		got, bals, err := readBankCSV(strings.NewReader(content), "bankA", prof)
+		if err != nil {
+			return
+		}
+		if len(bals) != 0 {
+			t.Fatalf("unexpected balances without balance column: %+v", bals)
+		}
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		w.Write([]string{"unique_identifier", "amount", "date"})
+		for _, b := range got {
+			w.Write([]string{b.UniqueIdentifier, strconv.FormatInt(b.Amount, 10), b.Date.Format("2006-01-02")})
+		}
+		w.Flush()
+		again, err := ReadBankCSV(&buf, "bankA", BankProfile{})
+		if err != nil {
+			t.Fatalf("re-read failed: %v\n%s", err, buf.String())
+		}
+		if len(again) != len(got) {
+			t.Fatalf("re-read %d rows, want %d", len(again), len(got))
+		}
+		for i := range got {
+			a, b := got[i], again[i]
+			if a.UniqueIdentifier != b.UniqueIdentifier || a.Amount != b.Amount || !a.Date.Equal(b.Date) || b.BankName != "bankA" {
+				t.Fatalf("row %d changed: %+v -> %+v", i, a, b)
+			}
+		}
+	})
+}
+
+// withThousands memformat v dengan koma pemisah ribuan, mis. -1250000 -> "-1,250,000".
+func withThousands(v int64) string {
+	s := strconv.FormatInt(v, 10)
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String()
+}
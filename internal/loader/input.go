@@ -0,0 +1,151 @@
+package loader
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zipMagic  = []byte("PK\x03\x04")
+)
+
+// Ekstensi entri zip yang dibaca untuk setiap format input.
+var (
+	csvExts     = []string{".csv"}
+	camt053Exts = []string{".xml"}
+	mt940Exts   = []string{".sta", ".mt940", ".940", ".txt"}
+)
+
+// forEachInput membuka file pada path dan memanggil fn untuk setiap stream
+// di dalamnya beserta nama entrinya. File gzip (.gz atau magic bytes 1f 8b)
+// didekompresi; file zip (.zip atau magic bytes PK) diiterasi per entri
+// berekstensi exts sesuai urutan nama. Nama entri kosong untuk file non-zip.
+func forEachInput(p string, exts []string, fn func(entry string, r io.Reader) error) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	head, _ := br.Peek(len(zipMagic))
+	ext := strings.ToLower(filepath.Ext(p))
+	switch {
+	case bytes.HasPrefix(head, zipMagic) || ext == ".zip":
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		return forEachZipEntry(f, info.Size(), exts, fn)
+	case bytes.HasPrefix(head, gzipMagic) || ext == ".gz":
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		return fn("", zr)
+	default:
+		return fn("", br)
+	}
+}
+
+// forEachReaderInput sama dengan forEachInput untuk reader arbitrer; jenis
+// kompresi hanya dideteksi dari magic bytes. Arsip zip dibaca penuh ke memori
+// karena zip membutuhkan akses acak.
+func forEachReaderInput(r io.Reader, exts []string, fn func(entry string, r io.Reader) error) error {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(len(zipMagic))
+	switch {
+	case bytes.HasPrefix(head, zipMagic):
+		raw, err := io.ReadAll(br)
+		if err != nil {
+			return err
+		}
+		return forEachZipEntry(bytes.NewReader(raw), int64(len(raw)), exts, fn)
+	case bytes.HasPrefix(head, gzipMagic):
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return err
+		}
+		defer zr.Close()
+		return fn("", zr)
+	default:
+		return fn("", br)
+	}
+}
+
+// forEachZipEntry memanggil fn untuk setiap entri berekstensi exts
+// (boleh diakhiri .gz) di dalam zip.
+func forEachZipEntry(ra io.ReaderAt, size int64, exts []string, fn func(entry string, r io.Reader) error) error {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return err
+	}
+	var entries []*zip.File
+	for _, zf := range zr.File {
+		if isInputEntry(zf, exts) {
+			entries = append(entries, zf)
+		}
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no %s entries in zip archive", strings.Join(exts, "/"))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	for _, zf := range entries {
+		if err := readZipEntry(zf, fn); err != nil {
+			return fmt.Errorf("zip entry %s: %w", zf.Name, err)
+		}
+	}
+	return nil
+}
+
+func readZipEntry(zf *zip.File, fn func(entry string, r io.Reader) error) error {
+	rc, err := zf.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	if !strings.HasSuffix(strings.ToLower(zf.Name), ".gz") {
+		return fn(zf.Name, rc)
+	}
+	gz, err := gzip.NewReader(rc)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	return fn(zf.Name, gz)
+}
+
+// entryAccount menurunkan nama rekening dari nama entri zip: basename tanpa
+// .gz dan ekstensi, mis. "2025-06/acc-001.csv.gz" menjadi "acc-001".
+func entryAccount(entry string) string {
+	base := path.Base(entry)
+	if strings.HasSuffix(strings.ToLower(base), ".gz") {
+		base = base[:len(base)-len(".gz")]
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+// isInputEntry melewati direktori, metadata macOS, dan file dengan ekstensi lain.
+func isInputEntry(zf *zip.File, exts []string) bool {
+	if zf.FileInfo().IsDir() || strings.HasPrefix(zf.Name, "__MACOSX/") || strings.HasPrefix(path.Base(zf.Name), ".") {
+		return false
+	}
+	n := strings.TrimSuffix(strings.ToLower(zf.Name), ".gz")
+	for _, ext := range exts {
+		if strings.HasSuffix(n, ext) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func gzipBytes(t *testing.T, content string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatalf("gzip write: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("gzip close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func writeZip(t *testing.T, p string, entries [][2]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e[0])
+		if err != nil {
+			t.Fatalf("zip create: %v", err)
+		}
+		if _, err := w.Write([]byte(e[1])); err != nil {
+			t.Fatalf("zip write: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip close: %v", err)
+	}
+	if err := os.WriteFile(p, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("write zip: %v", err)
+	}
+}
+
+func TestLoadSystemCSV_Gzip(t *testing.T) {
+	dir := t.TempDir()
+	content := "trxID,amount,type,transactionTime\n" +
+		"TRX-1,250000,CREDIT,2025-06-01T12:34:56Z\n"
+	// Deteksi lewat ekstensi maupun magic bytes (tanpa ekstensi .gz).
+	for _, name := range []string{"system.csv.gz", "system.csv"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, gzipBytes(t, content), 0644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		got, err := LoadSystemCSV(p)
+		if err != nil {
+			t.Fatalf("LoadSystemCSV(%s) error: %v", name, err)
+		}
+		if len(got) != 1 || got[0].TrxID != "TRX-1" || got[0].Amount != 250000 {
+			t.Fatalf("unexpected rows from %s: %+v", name, got)
+		}
+	}
+}
+
+func TestLoadBankCSV_Zip(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "bankA.zip")
+	writeZip(t, p, [][2]string{
+		{"acc-002.csv", "unique_identifier,amount,date\nBA-3,300,2025-06-02\n"},
+		{"readme.txt", "ignored"},
+		{"__MACOSX/._acc-001.csv", "ignored"},
+		{"acc-001.csv", "unique_identifier,amount,date\nBA-1,100,2025-06-01\nBA-2,-200,2025-06-01\n"},
+		{"acc-003.csv.gz", string(gzipBytes(t, "unique_identifier,amount,date\nBA-4,400,2025-06-03\n"))},
+	})
+	got, err := LoadBankCSV(p, "bankA")
+	if err != nil {
+		t.Fatalf("LoadBankCSV error: %v", err)
+	}
+	if len(got) != 4 {
+		t.Fatalf("len(got)=%d", len(got))
+	}
+	accounts := []string{"acc-001", "acc-001", "acc-002", "acc-003"}
+	for i, id := range []string{"BA-1", "BA-2", "BA-3", "BA-4"} {
+		if got[i].UniqueIdentifier != id || got[i].BankName != "bankA" || got[i].Account != accounts[i] {
+			t.Fatalf("unexpected row %d: %+v", i, got[i])
+		}
+	}
+}
+
+func TestLoadBankCSV_ZipErrors(t *testing.T) {
+	dir := t.TempDir()
+	empty := filepath.Join(dir, "empty.zip")
+	writeZip(t, empty, [][2]string{{"readme.txt", "no csv"}})
+	if _, err := LoadBankCSV(empty, "bankA"); err == nil {
+		t.Fatalf("expected error for zip without csv entries")
+	}
+
+	bad := filepath.Join(dir, "bad.zip")
+	writeZip(t, bad, [][2]string{{"a.csv", "unique_identifier,amount,date\nBA-1,x,2025-06-01\n"}})
+	if _, err := LoadBankCSV(bad, "bankA"); err == nil {
+		t.Fatalf("expected error for invalid amount inside zip")
+	}
+
+	notGzip := writeTempFile(t, dir, "plain.csv.gz", "unique_identifier,amount,date\n")
+	if _, err := LoadBankCSV(notGzip, "bankA"); err == nil {
+		t.Fatalf("expected error for invalid gzip")
+	}
+}
+
+func TestLoadBankFiles_ZipEntryPerBank(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "mutasi.zip")
+	writeZip(t, p, [][2]string{
+		{"bankA.csv", "unique_identifier,amount,date\nBA-1,100,2025-06-01\n"},
+		{"bankB.csv", "ref;nominal;tanggal\nBB-1;200;01/06/2025\n"},
+	})
+	profB := BankProfile{CSVFormat: CSVFormat{Delimiter: ";"}, IDColumn: "ref", AmountColumn: "nominal", DateColumn: "tanggal", DateLayout: "02/01/2006"}
+
+	// Tanpa EntryPattern semua entri dibaca sebagai satu bank dengan satu profile.
+	got, err := LoadBankCSV(p, "mutasi")
+	if err == nil {
+		t.Fatalf("expected error for bankB entry read with default profile, got %+v", got)
+	}
+	pattern, err := CompileNamePattern("{bank}.csv")
+	if err != nil {
+		t.Fatalf("CompileNamePattern error: %v", err)
+	}
+	banks, err := LoadBankFiles([]BankFile{{
+		Name:          "mutasi",
+		Path:          p,
+		EntryPattern:  pattern,
+		EntryProfiles: map[string]BankProfile{"bankB": profB},
+	}}, 1)
+	if err != nil {
+		t.Fatalf("LoadBankFiles error: %v", err)
+	}
+	if len(banks) != 2 || len(banks["bankA"]) != 1 || len(banks["bankB"]) != 1 {
+		t.Fatalf("expected one statement per bank, got %+v", banks)
+	}
+	a, b := banks["bankA"][0], banks["bankB"][0]
+	if a.UniqueIdentifier != "BA-1" || a.BankName != "bankA" || a.Account != "bankA" || a.Source != p {
+		t.Fatalf("unexpected bankA statement: %+v", a)
+	}
+	if b.UniqueIdentifier != "BB-1" || b.Amount != 200 || b.BankName != "bankB" || b.Account != "bankB" {
+		t.Fatalf("unexpected bankB statement: %+v", b)
+	}
+}
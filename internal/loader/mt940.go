@@ -0,0 +1,256 @@
+package loader
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// LoadMT940 membaca bank statement SWIFT MT940 dari file (boleh .gz, atau .zip
+// berisi beberapa file statement). Satu file boleh berisi beberapa statement.
+func LoadMT940(path, bankName string) ([]BankStatement, error) {
+	return NewMT940File(path, bankName).BankStatements()
+}
+
+// ReadMT940 membaca bank statement MT940 dari reader.
+func ReadMT940(r io.Reader, bankName string) ([]BankStatement, error) {
+	return NewMT940Reader(r, bankName).BankStatements()
+}
+
+// NewMT940File membuat BankSource MT940 dari file.
+func NewMT940File(path, bankName string) BankSource {
+	return mt940{open: fileOpener(path, mt940Exts), source: path, name: bankName}
+}
+
+// NewMT940Reader membuat BankSource MT940 dari reader. Reader hanya dapat
+// dibaca sekali.
+func NewMT940Reader(r io.Reader, bankName string) BankSource {
+	return mt940{open: readerOpener(r, mt940Exts), name: bankName}
+}
+
+type mt940 struct {
+	open   opener
+	source string
+	name   string
+}
+
+func (m mt940) BankName() string { return m.name }
+
+func (m mt940) BankStatements() ([]BankStatement, error) {
+	out, _, err := m.BankStatementsWithBalances()
+	return out, err
+}
+
+func (m mt940) BankStatementsWithBalances() ([]BankStatement, []StatementBalance, error) {
+	var out []BankStatement
+	var bals []StatementBalance
+	err := m.open(func(_ string, r io.Reader) error {
+		bs, bl, err := readMT940(r, m.name)
+		bl = withSource(bs, bl, m.name, m.source)
+		out = append(out, bs...)
+		bals = append(bals, bl...)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, bals, nil
+}
+
+// mt940Field adalah satu field bertag, mis. {tag: "61", value: "2506010601C..."}.
+// Baris lanjutan digabung ke value dengan "\n".
+type mt940Field struct {
+	tag   string
+	value string
+	line  int
+}
+
+var mt940TagRe = regexp.MustCompile(`^:([0-9]{2}[A-Z]?):(.*)$`)
+
+// splitMT940Fields memecah pesan MT940 menjadi field bertag. Pembungkus blok
+// SWIFT ({1:..}{2:..}{4:) dan terminator "-" / "-}" diabaikan.
+func splitMT940Fields(r io.Reader) ([]mt940Field, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	var fields []mt940Field
+	n := 0
+	for sc.Scan() {
+		n++
+		line := strings.TrimRight(sc.Text(), "\r ")
+		if i := strings.Index(line, "{4:"); i >= 0 {
+			line = line[i+len("{4:"):]
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || trimmed == "-" || strings.HasPrefix(trimmed, "-}") || strings.HasPrefix(trimmed, "{") {
+			continue
+		}
+		if m := mt940TagRe.FindStringSubmatch(line); m != nil {
+			fields = append(fields, mt940Field{tag: m[1], value: m[2], line: n})
+			continue
+		}
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("line %d: expected MT940 field tag, got %q", n, line)
+		}
+		fields[len(fields)-1].value += "\n" + line
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// Subfield :61: — tanggal valuta (YYMMDD), tanggal entry opsional (MMDD),
+// tanda D/C/RD/RC, kode dana opsional, amount (koma desimal), tipe transaksi,
+// referensi pemilik rekening, //referensi bank opsional, lalu baris kedua
+// opsional berisi supplementary details.
+var mt940Line61Re = regexp.MustCompile(`^([0-9]{6})([0-9]{4})?(RC|RD|C|D)([A-Z])?([0-9]+,[0-9]*)([NSF][A-Z0-9]{3})([^/\n]*)(?://([^\n]*))?(?:\n([\s\S]*))?$`)
+
+// mt940BalanceRe mengurai saldo :60F:/:60M:/:62F:/:62M: — tanda D/C,
+// tanggal (YYMMDD), mata uang, dan amount (koma desimal).
+var mt940BalanceRe = regexp.MustCompile(`^([CD])([0-9]{6})([A-Z]{3})([0-9]+,[0-9]*)$`)
+
+// readMT940 membaca semua statement di dalam satu pesan MT940. Setiap :61:
+// menjadi satu BankStatement dengan narasi dari :86: yang mengikutinya.
+// Date memakai tanggal entry (booking) bila ada, selain itu tanggal valuta.
+// Setiap pasangan saldo awal (:60F:/:60M:) dan akhir (:62F:/:62M:) menjadi
+// satu StatementBalance.
+func readMT940(r io.Reader, bankName string) ([]BankStatement, []StatementBalance, error) {
+	fields, err := splitMT940Fields(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	var out []BankStatement
+	var bals []StatementBalance
+	var stmtRef, account string
+	var cur *StatementBalance // saldo statement yang sedang dibaca
+	seq := 0
+	last := -1 // indeks BankStatement terakhir untuk :86:
+	for _, f := range fields {
+		switch f.tag {
+		case "20":
+			stmtRef, account, seq, last, cur = strings.TrimSpace(f.value), "", 0, -1, nil
+		case "25":
+			account = strings.TrimSpace(f.value)
+		case "60F", "60M":
+			amt, _, err := parseMT940Balance(f.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid :%s: %q: %w", f.line, f.tag, f.value, err)
+			}
+			cur = &StatementBalance{Statement: stmtRef, Account: account, Opening: amt}
+		case "61":
+			seq++
+			b, err := parseMT940Line61(f.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid :61: %q: %w", f.line, f.value, err)
+			}
+			b.BankName = bankName
+			b.Account = account
+			if b.UniqueIdentifier == "" {
+				b.UniqueIdentifier = fmt.Sprintf("%s-%d", stmtRef, seq)
+			}
+			out = append(out, b)
+			last = len(out) - 1
+			if cur != nil {
+				cur.Total += b.Amount
+				cur.Count++
+			}
+		case "62F", "62M":
+			amt, date, err := parseMT940Balance(f.value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("line %d: invalid :%s: %q: %w", f.line, f.tag, f.value, err)
+			}
+			if cur != nil {
+				cur.Closing, cur.Date = amt, date
+				bals = append(bals, *cur)
+				cur = nil
+			}
+		case "86":
+			if last >= 0 {
+				out[last].Description = joinLines(f.value)
+				last = -1
+			}
+		}
+	}
+	return out, bals, nil
+}
+
+func parseMT940Balance(v string) (int64, time.Time, error) {
+	m := mt940BalanceRe.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return 0, time.Time{}, fmt.Errorf("unrecognised balance")
+	}
+	date, err := time.Parse("060102", m[2])
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid balance date: %w", err)
+	}
+	amt, err := parseDecimal(m[4], ',')
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid balance amount %q: %w", m[4], err)
+	}
+	if m[1] == "D" {
+		amt = -amt
+	}
+	return amt, date, nil
+}
+
+func parseMT940Line61(v string) (BankStatement, error) {
+	m := mt940Line61Re.FindStringSubmatch(v)
+	if m == nil {
+		return BankStatement{}, fmt.Errorf("unrecognised statement line")
+	}
+	valueDate, err := time.Parse("060102", m[1])
+	if err != nil {
+		return BankStatement{}, fmt.Errorf("invalid value date: %w", err)
+	}
+	entryDate := valueDate
+	if m[2] != "" {
+		if entryDate, err = mt940EntryDate(valueDate, m[2]); err != nil {
+			return BankStatement{}, err
+		}
+	}
+	amt, err := parseDecimal(m[5], ',')
+	if err != nil {
+		return BankStatement{}, fmt.Errorf("invalid amount %q: %w", m[5], err)
+	}
+	// C dan RD (reversal debit) menambah saldo; D dan RC mengurangi.
+	if m[3] == "D" || m[3] == "RC" {
+		amt = -amt
+	}
+	ownerRef := strings.TrimSpace(m[7])
+	if strings.EqualFold(ownerRef, "NONREF") {
+		ownerRef = ""
+	}
+	bankRef := strings.TrimSpace(m[8])
+	return BankStatement{
+		UniqueIdentifier: firstNonEmpty(bankRef, ownerRef),
+		Amount:           amt,
+		Date:             entryDate,
+		ValueDate:        valueDate,
+		Reference:        ownerRef,
+		Description:      joinLines(m[9]),
+	}, nil
+}
+
+// mt940EntryDate melengkapi tanggal entry (MMDD) dengan tahun dari tanggal
+// valuta, termasuk saat entry dan valuta berada di tahun yang berbeda.
+func mt940EntryDate(valueDate time.Time, mmdd string) (time.Time, error) {
+	d, err := time.Parse("0102", mmdd)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid entry date %q: %w", mmdd, err)
+	}
+	year := valueDate.Year()
+	switch {
+	case valueDate.Month() == time.January && d.Month() == time.December:
+		year--
+	case valueDate.Month() == time.December && d.Month() == time.January:
+		year++
+	}
+	return time.Date(year, d.Month(), d.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func joinLines(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
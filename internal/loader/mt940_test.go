@@ -0,0 +1,53 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadMT940_MultiStatement(t *testing.T) {
+	got, err := LoadMT940("testdata/mt940_bankB.sta", "bankB")
+	if err != nil {
+		t.Fatalf("LoadMT940 error: %v", err)
+	}
+	if len(got) != 5 {
+		t.Fatalf("expected 5 statement lines, got %d: %+v", len(got), got)
+	}
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	cases := []struct {
+		id, account, ref, desc string
+		amount                 int64
+		date, valueDate        time.Time
+	}{
+		{"BB-3001", "BANKIDJA/0001234567", "TRX-1001", "Angsuran pinjaman L-0042 Siti Aminah", 250000, day(2025, 6, 1), day(2025, 6, 1)},
+		{"STMT-0601-2", "BANKIDJA/0001234567", "", "Pencairan pinjaman L-0050", -125000, day(2025, 6, 1), day(2025, 6, 1)},
+		// RC (reversal kredit) mengurangi saldo; tanggal entry lebih awal dari valuta.
+		{"BB-3003", "BANKIDJA/0001234567", "", "KOREKSI KREDIT", -42000, day(2025, 6, 1), day(2025, 6, 2)},
+		// Tanggal entry 31 Des pada valuta 1 Jan berada di tahun sebelumnya.
+		{"REF-77", "0009876543", "REF-77", "", 75000, day(2024, 12, 31), day(2025, 1, 1)},
+		{"STMT-0101-2", "0009876543", "", "Biaya administrasi", -5000, day(2025, 1, 1), day(2025, 1, 1)},
+	}
+	for i, c := range cases {
+		b := got[i]
+		if b.UniqueIdentifier != c.id || b.Account != c.account || b.Reference != c.ref || b.Description != c.desc ||
+			b.Amount != c.amount || !b.Date.Equal(c.date) || !b.ValueDate.Equal(c.valueDate) || b.BankName != "bankB" {
+			t.Fatalf("unexpected line %d: %+v", i, b)
+		}
+	}
+}
+
+func TestReadMT940_Invalid(t *testing.T) {
+	cases := map[string]string{
+		"garbage":      "hello\n",
+		"bad line":     ":20:S\n:61:2506XXC100,00NTRFNONREF\n",
+		"fraction":     ":20:S\n:61:250601C100,50NTRFNONREF\n",
+		"bad entry dt": ":20:S\n:61:2506011345C100,00NTRFNONREF\n",
+	}
+	for name, content := range cases {
+		if _, err := ReadMT940(strings.NewReader(content), "bankB"); err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+	}
+}
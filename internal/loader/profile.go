@@ -0,0 +1,344 @@
+package loader
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode penentuan tanda amount bank (BankProfile.SignMode).
+const (
+	SignSigned      = "signed"       // satu kolom amount bertanda (default)
+	SignDebitCredit = "debit_credit" // kolom debit dan kredit terpisah
+	SignIndicator   = "indicator"    // kolom amount + kolom indikator DB/CR
+)
+
+var (
+	defaultDebitIndicators  = []string{"D", "DB", "DR", "DEBIT"}
+	defaultCreditIndicators = []string{"C", "CR", "K", "KR", "CREDIT", "KREDIT"}
+)
+
+// SystemProfile memetakan kolom CSV transaksi sistem berdasarkan nama header.
+// Nama kolom kosong berarti memakai posisi default
+// (trxID,amount,type,transactionTime). Dialek file diatur lewat CSVFormat.
+type SystemProfile struct {
+	CSVFormat
+	IDColumn     string `json:"id_column"`
+	AmountColumn string `json:"amount_column"`
+	TypeColumn   string `json:"type_column"`
+	TimeColumn   string `json:"time_column"`
+
+	// AccountColumn (opsional) berisi bank/rekening tujuan transaksi. Jika
+	// kosong atau nilainya kosong, AccountRules dipakai untuk menurunkannya.
+	AccountColumn string        `json:"account_column"`
+	AccountRules  []AccountRule `json:"account_rules"`
+
+	// DescriptionColumn (opsional) berisi narasi transaksi.
+	DescriptionColumn string `json:"description_column"`
+
+	// Types memetakan nilai kolom type (tanpa membedakan huruf besar/kecil)
+	// ke CREDIT atau DEBIT, mis. {"REVERSAL": "DEBIT"}; melengkapi kosakata
+	// bawaan CREDIT, DEBIT, CR, dan DR.
+	Types map[string]string `json:"types"`
+	// UnknownTypes menentukan perlakuan type di luar kosakata: "reject"
+	// (default) atau "report".
+	UnknownTypes string `json:"unknown_types"`
+
+	// TimeLayouts adalah layout Go kolom waktu yang dicoba berurutan (boleh
+	// juga epoch_s/epoch_ms, atau auto untuk deteksi otomatis); kosong
+	// berarti RFC3339.
+	TimeLayouts []string `json:"time_layouts"`
+	// Timezone adalah zona IANA untuk waktu tanpa zona; default UTC.
+	Timezone string `json:"timezone"`
+}
+
+// BankProfile memetakan kolom CSV bank statement berdasarkan nama header.
+// Nama kolom kosong berarti memakai posisi default (unique_identifier,amount,date).
+// Dialek file diatur lewat CSVFormat, tanda amount lewat SignMode.
+type BankProfile struct {
+	CSVFormat
+	IDColumn     string `json:"id_column"`
+	AmountColumn string `json:"amount_column"`
+	DateColumn   string `json:"date_column"`
+	DateLayout   string `json:"date_layout"` // layout Go atau auto; kosong = 2006-01-02
+
+	// DateLayouts adalah layout tambahan yang dicoba setelah DateLayout
+	// (boleh juga epoch_s/epoch_ms). Timezone (zona IANA) menentukan tanggal
+	// dari nilai berzona atau epoch; tanpa Timezone tanggal diambil apa adanya.
+	DateLayouts []string `json:"date_layouts"`
+	Timezone    string   `json:"timezone"`
+
+	// BalanceColumn, jika diisi, adalah saldo berjalan setelah setiap baris;
+	// dipakai untuk verifikasi saldo awal + mutasi = saldo akhir per tanggal.
+	BalanceColumn string `json:"balance_column"`
+
+	// DescriptionColumn dan ReferenceColumn (opsional) mengisi Description
+	// dan Reference statement.
+	DescriptionColumn string `json:"description_column"`
+	ReferenceColumn   string `json:"reference_column"`
+
+	// SignMode: signed (default), debit_credit (DebitColumn & CreditColumn
+	// wajib; amount = kredit - debit) atau indicator (IndicatorColumn wajib;
+	// amount dinegatifkan bila indikator termasuk DebitIndicators).
+	SignMode         string   `json:"sign_mode"`
+	DebitColumn      string   `json:"debit_column"`
+	CreditColumn     string   `json:"credit_column"`
+	IndicatorColumn  string   `json:"indicator_column"`
+	DebitIndicators  []string `json:"debit_indicators"`  // default D, DB, DR, DEBIT
+	CreditIndicators []string `json:"credit_indicators"` // default C, CR, K, KR, CREDIT, KREDIT
+}
+
+// systemColumns menyimpan indeks kolom hasil resolusi SystemProfile.
+type systemColumns struct {
+	id, amount, typ, time int
+	account, description  int // -1 jika kolom opsional tidak dipakai
+}
+
+func (c systemColumns) max() int {
+	return maxInt(c.id, c.amount, c.typ, c.time, c.account, c.description)
+}
+
+// bankColumns menyimpan indeks kolom hasil resolusi BankProfile; kolom yang
+// tidak dipakai SignMode bernilai -1.
+type bankColumns struct {
+	id, amount, date         int
+	debit, credit, indicator int
+	balance                  int
+	description, reference   int
+}
+
+func (c bankColumns) max() int {
+	return maxInt(c.id, c.amount, c.date, c.debit, c.credit, c.indicator, c.balance, c.description, c.reference)
+}
+
+func (p SystemProfile) columns(header []string) (systemColumns, error) {
+	var c systemColumns
+	var err error
+	if c.id, err = columnIndex(header, p.IDColumn, 0); err != nil {
+		return c, err
+	}
+	if c.amount, err = columnIndex(header, p.AmountColumn, 1); err != nil {
+		return c, err
+	}
+	if c.typ, err = columnIndex(header, p.TypeColumn, 2); err != nil {
+		return c, err
+	}
+	if c.time, err = columnIndex(header, p.TimeColumn, 3); err != nil {
+		return c, err
+	}
+	if c.account, err = columnIndex(header, p.AccountColumn, -1); err != nil {
+		return c, err
+	}
+	if c.description, err = columnIndex(header, p.DescriptionColumn, -1); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// Validate memeriksa dialek file dan AccountRules.
+func (p SystemProfile) Validate() error {
+	if err := p.CSVFormat.Validate(); err != nil {
+		return err
+	}
+	if _, err := compileTypes(p.Types, p.UnknownTypes); err != nil {
+		return err
+	}
+	if _, err := p.timeParser(); err != nil {
+		return err
+	}
+	_, err := compileAccountRules(p.AccountRules)
+	return err
+}
+
+func (p BankProfile) columns(header []string) (bankColumns, error) {
+	c := bankColumns{amount: -1, debit: -1, credit: -1, indicator: -1, balance: -1, description: -1, reference: -1}
+	var err error
+	if c.id, err = columnIndex(header, p.IDColumn, 0); err != nil {
+		return c, err
+	}
+	if p.signMode() == SignDebitCredit {
+		if c.debit, err = columnIndex(header, p.DebitColumn, -1); err != nil {
+			return c, err
+		}
+		if c.credit, err = columnIndex(header, p.CreditColumn, -1); err != nil {
+			return c, err
+		}
+	} else if c.amount, err = columnIndex(header, p.AmountColumn, 1); err != nil {
+		return c, err
+	}
+	if p.signMode() == SignIndicator {
+		if c.indicator, err = columnIndex(header, p.IndicatorColumn, -1); err != nil {
+			return c, err
+		}
+	}
+	if c.date, err = columnIndex(header, p.DateColumn, 2); err != nil {
+		return c, err
+	}
+	if c.balance, err = columnIndex(header, p.BalanceColumn, -1); err != nil {
+		return c, err
+	}
+	if c.description, err = columnIndex(header, p.DescriptionColumn, -1); err != nil {
+		return c, err
+	}
+	if c.reference, err = columnIndex(header, p.ReferenceColumn, -1); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// Validate memeriksa dialek file dan konfigurasi SignMode.
+func (p BankProfile) Validate() error {
+	if err := p.CSVFormat.Validate(); err != nil {
+		return err
+	}
+	switch p.signMode() {
+	case SignSigned:
+	case SignDebitCredit:
+		if p.DebitColumn == "" || p.CreditColumn == "" {
+			return fmt.Errorf("sign_mode %s requires debit_column and credit_column", SignDebitCredit)
+		}
+	case SignIndicator:
+		if p.IndicatorColumn == "" {
+			return fmt.Errorf("sign_mode %s requires indicator_column", SignIndicator)
+		}
+	default:
+		return fmt.Errorf("unknown sign_mode %q", p.SignMode)
+	}
+	_, err := p.dateParser()
+	return err
+}
+
+func (p BankProfile) signMode() string {
+	if p.SignMode == "" {
+		return SignSigned
+	}
+	return p.SignMode
+}
+
+// amount menghitung amount bertanda dari satu baris sesuai SignMode.
+func (p BankProfile) amount(rec []string, c bankColumns) (int64, error) {
+	switch p.signMode() {
+	case SignDebitCredit:
+		if blankAmount(rec[c.debit]) && blankAmount(rec[c.credit]) {
+			return 0, fmt.Errorf("row has neither debit nor credit")
+		}
+		debit, err := p.unsignedAmount(rec[c.debit])
+		if err != nil {
+			return 0, fmt.Errorf("invalid debit %q: %w", rec[c.debit], err)
+		}
+		credit, err := p.unsignedAmount(rec[c.credit])
+		if err != nil {
+			return 0, fmt.Errorf("invalid credit %q: %w", rec[c.credit], err)
+		}
+		return credit - debit, nil
+	case SignIndicator:
+		amt, err := p.unsignedAmount(rec[c.amount])
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", rec[c.amount], err)
+		}
+		ind := strings.TrimSpace(rec[c.indicator])
+		switch {
+		case containsFold(p.debitIndicators(), ind):
+			return -amt, nil
+		case containsFold(p.creditIndicators(), ind):
+			return amt, nil
+		}
+		return 0, fmt.Errorf("unknown debit/credit indicator %q", ind)
+	default:
+		amt, err := p.parseAmount(rec[c.amount])
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q: %w", rec[c.amount], err)
+		}
+		return amt, nil
+	}
+}
+
+// unsignedAmount mengurai nilai kolom debit/kredit/amount yang tandanya
+// ditentukan kolom lain; sel kosong atau "-" berarti nol dan tanda minus
+// dari bank diabaikan.
+func (p BankProfile) unsignedAmount(s string) (int64, error) {
+	if blankAmount(s) {
+		return 0, nil
+	}
+	s = strings.TrimSpace(s)
+	v, err := p.parseAmount(s)
+	if err != nil {
+		return 0, err
+	}
+	if v < 0 {
+		v = -v
+	}
+	return v, nil
+}
+
+// blankAmount melaporkan apakah sel amount kosong atau berisi "-".
+func blankAmount(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || s == "-"
+}
+
+func (p BankProfile) debitIndicators() []string {
+	if len(p.DebitIndicators) == 0 {
+		return defaultDebitIndicators
+	}
+	return p.DebitIndicators
+}
+
+func (p BankProfile) creditIndicators() []string {
+	if len(p.CreditIndicators) == 0 {
+		return defaultCreditIndicators
+	}
+	return p.CreditIndicators
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(strings.TrimSpace(v), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p SystemProfile) timeParser() (timeParser, error) {
+	return newTimeParser(p.TimeLayouts, defaultTimeLayouts, p.Timezone)
+}
+
+func (p BankProfile) dateParser() (timeParser, error) {
+	layouts := p.DateLayouts
+	if p.DateLayout != "" {
+		layouts = append([]string{p.DateLayout}, p.DateLayouts...)
+	}
+	return newTimeParser(layouts, defaultDateLayouts, p.Timezone)
+}
+
+// columnIndex mencari posisi kolom berdasarkan nama header (case-insensitive).
+// Jika nama kosong, posisi default dikembalikan.
+func columnIndex(header []string, name string, def int) (int, error) {
+	if name == "" {
+		return def, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(h), name) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("column %q not found in header %v", name, header)
+}
+
+// optionalField mengembalikan nilai kolom opsional, atau "" jika idx < 0.
+func optionalField(rec []string, idx int) string {
+	if idx < 0 {
+		return ""
+	}
+	return strings.TrimSpace(rec[idx])
+}
+
+func maxInt(vs ...int) int {
+	m := vs[0]
+	for _, v := range vs[1:] {
+		if v > m {
+			m = v
+		}
+	}
+	return m
+}
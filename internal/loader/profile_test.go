@@ -0,0 +1,200 @@
+package loader
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestReadBankCSV_DebitCreditColumns(t *testing.T) {
+	content := "tanggal;ref;debit;kredit\n" +
+		"2025-06-01;BA-1;;1.250.000,00\n" +
+		"2025-06-01;BA-2;125.000,00;\n" +
+		"2025-06-02;BA-3;-5.000;0\n"
+	prof := BankProfile{
+		CSVFormat:    CSVFormat{Delimiter: ";", Locale: LocaleID},
+		IDColumn:     "ref",
+		DateColumn:   "tanggal",
+		SignMode:     SignDebitCredit,
+		DebitColumn:  "debit",
+		CreditColumn: "kredit",
+	}
+	got, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	want := []int64{1250000, -125000, -5000}
+	if len(got) != len(want) {
+		t.Fatalf("len(got)=%d", len(got))
+	}
+	for i, w := range want {
+		if got[i].Amount != w {
+			t.Fatalf("row %d amount=%d want %d", i, got[i].Amount, w)
+		}
+	}
+
+	bad := "tanggal;ref;debit;kredit\n2025-06-01;BA-1;abc;\n"
+	if _, err := ReadBankCSV(bytes.NewBufferString(bad), "bankA", prof); err == nil {
+		t.Fatalf("expected error for invalid debit")
+	}
+
+	for _, row := range []string{"2025-06-01;BA-1;;", "2025-06-01;BA-1;-; - "} {
+		_, err := ReadBankCSV(bytes.NewBufferString("tanggal;ref;debit;kredit\n"+row+"\n"), "bankA", prof)
+		if err == nil || !strings.Contains(err.Error(), "neither debit nor credit") {
+			t.Fatalf("expected error for row without debit and credit %q, got %v", row, err)
+		}
+	}
+}
+
+func TestReadBankCSV_Indicator(t *testing.T) {
+	content := "unique_identifier,amount,dc,date\n" +
+		"BA-1,250000,CR,2025-06-01\n" +
+		"BA-2,125000,db,2025-06-01\n" +
+		"BA-3,75000,K,2025-06-02\n"
+	prof := BankProfile{SignMode: SignIndicator, IndicatorColumn: "dc", DateColumn: "date"}
+	got, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if len(got) != 3 || got[0].Amount != 250000 || got[1].Amount != -125000 || got[2].Amount != 75000 {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+
+	custom := prof
+	custom.DebitIndicators = []string{"OUT"}
+	custom.CreditIndicators = []string{"IN"}
+	content = "unique_identifier,amount,dc,date\nBA-4,5000,out,2025-06-02\n"
+	got, err = ReadBankCSV(bytes.NewBufferString(content), "bankA", custom)
+	if err != nil || len(got) != 1 || got[0].Amount != -5000 {
+		t.Fatalf("custom indicators: got %+v, err %v", got, err)
+	}
+
+	content = "unique_identifier,amount,dc,date\nBA-5,5000,X,2025-06-02\n"
+	if _, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", prof); err == nil {
+		t.Fatalf("expected error for unknown indicator")
+	}
+}
+
+func TestBankProfile_Validate(t *testing.T) {
+	bad := []BankProfile{
+		{SignMode: "reverse"},
+		{SignMode: SignDebitCredit, DebitColumn: "debit"},
+		{SignMode: SignIndicator},
+		{CSVFormat: CSVFormat{Locale: "fr"}},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", p)
+		}
+	}
+	if err := (BankProfile{SignMode: SignDebitCredit, DebitColumn: "d", CreditColumn: "c"}).Validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReadSystemCSV_Account(t *testing.T) {
+	content := "trxID,amount,type,transactionTime,rekening\n" +
+		"TRX-1,1000,CREDIT,2025-06-01T00:00:00Z,bankB\n" +
+		"VA-BCA-2,2000,CREDIT,2025-06-01T00:00:00Z,\n" +
+		"TRX-3,3000,DEBIT,2025-06-01T00:00:00Z,\n" +
+		"TRX-4,4000,CREDIT,2025-06-01T00:00:00Z,\n"
+	prof := SystemProfile{
+		AccountColumn: "rekening",
+		AccountRules: []AccountRule{
+			{IDPattern: "^VA-BCA-", Account: "bankA"},
+			{Type: "debit", Account: "bankC"},
+		},
+	}
+	got, err := ReadSystemCSV(bytes.NewBufferString(content), prof)
+	if err != nil {
+		t.Fatalf("ReadSystemCSV error: %v", err)
+	}
+	want := []string{"bankB", "bankA", "bankC", ""}
+	if len(got) != len(want) {
+		t.Fatalf("len(got)=%d", len(got))
+	}
+	for i, w := range want {
+		if got[i].Account != w {
+			t.Fatalf("row %d account=%q want %q", i, got[i].Account, w)
+		}
+	}
+
+	bad := []SystemProfile{
+		{AccountRules: []AccountRule{{IDPattern: "(", Account: "bankA"}}},
+		{AccountRules: []AccountRule{{IDPattern: "^X"}}},
+		{AccountRules: []AccountRule{{Account: "bankA"}}},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", p.AccountRules)
+		}
+	}
+}
+
+func TestReadCSV_DescriptionColumns(t *testing.T) {
+	bank := "unique_identifier,amount,date,ref,narasi\nBA-1,250000,2025-06-01,TRX-1, Angsuran L-0042 \n"
+	got, err := ReadBankCSV(bytes.NewBufferString(bank), "bankA", BankProfile{DescriptionColumn: "narasi", ReferenceColumn: "ref"})
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if len(got) != 1 || got[0].Description != "Angsuran L-0042" || got[0].Reference != "TRX-1" {
+		t.Fatalf("unexpected bank rows: %+v", got)
+	}
+
+	sys := "trxID,amount,type,transactionTime,keterangan\nTRX-1,250000,CREDIT,2025-06-01T00:00:00Z,Siti Aminah\n"
+	txs, err := ReadSystemCSV(bytes.NewBufferString(sys), SystemProfile{DescriptionColumn: "keterangan"})
+	if err != nil {
+		t.Fatalf("ReadSystemCSV error: %v", err)
+	}
+	if len(txs) != 1 || txs[0].Description != "Siti Aminah" {
+		t.Fatalf("unexpected system rows: %+v", txs)
+	}
+}
+
+func TestReadSystemCSV_Types(t *testing.T) {
+	content := "trxID,amount,type,transactionTime\n" +
+		"TRX-1,1000,cr,2025-06-01T00:00:00Z\n" +
+		"TRX-2,2000, Dr ,2025-06-01T00:00:00Z\n" +
+		"TRX-3,3000,Reversal,2025-06-01T00:00:00Z\n" +
+		"TRX-4,4000,DEBT,2025-06-01T00:00:00Z\n"
+
+	_, err := ReadSystemCSV(bytes.NewBufferString(content), SystemProfile{})
+	if err == nil || !strings.Contains(err.Error(), `"Reversal"`) || !strings.Contains(err.Error(), "TRX-3") {
+		t.Fatalf("expected unknown type error for TRX-3, got %v", err)
+	}
+
+	prof := SystemProfile{
+		Types:        map[string]string{"reversal": "debit"},
+		UnknownTypes: UnknownTypeReport,
+		AccountRules: []AccountRule{{Type: "DEBIT", Account: "bankC"}},
+	}
+	got, err := ReadSystemCSV(bytes.NewBufferString(content), prof)
+	if err != nil {
+		t.Fatalf("ReadSystemCSV error: %v", err)
+	}
+	want := []struct{ typ, account string }{
+		{TypeCredit, ""},
+		{TypeDebit, "bankC"},
+		{TypeDebit, "bankC"},
+		{"DEBT", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("len(got)=%d", len(got))
+	}
+	for i, w := range want {
+		if got[i].Type != w.typ || got[i].Account != w.account {
+			t.Fatalf("row %d: got type %q account %q, want %q %q", i, got[i].Type, got[i].Account, w.typ, w.account)
+		}
+	}
+
+	bad := []SystemProfile{
+		{Types: map[string]string{"REV": "refund"}},
+		{Types: map[string]string{" ": "DEBIT"}},
+		{UnknownTypes: "ignore"},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", p)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package loader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"amartha/internal/model"
+)
+
+// SystemSource menyediakan transaksi sistem dari sumber apa pun
+// (file, upload HTTP, stdin, fixture in-memory).
+type SystemSource interface {
+	SystemTransactions() ([]model.SystemTransaction, error)
+}
+
+// BankSource menyediakan bank statement milik satu bank.
+type BankSource interface {
+	BankName() string
+	BankStatements() ([]BankStatement, error)
+}
+
+// NewSystemCSVFile membuat SystemSource dari file CSV (boleh .gz/.zip).
+func NewSystemCSVFile(path string, p SystemProfile) SystemSource {
+	return systemCSV{open: fileOpener(path, csvExts), profile: p}
+}
+
+// NewSystemCSVReader membuat SystemSource dari reader. Reader hanya dapat
+// dibaca sekali.
+func NewSystemCSVReader(r io.Reader, p SystemProfile) SystemSource {
+	return systemCSV{open: readerOpener(r, csvExts), profile: p}
+}
+
+// NewBankCSVFile membuat BankSource dari file CSV (boleh .gz/.zip).
+func NewBankCSVFile(path, bankName string, p BankProfile) BankSource {
+	return bankCSV{open: fileOpener(path, csvExts), source: path, name: bankName, profile: p}
+}
+
+// NewBankCSVReader membuat BankSource dari reader. Reader hanya dapat
+// dibaca sekali.
+func NewBankCSVReader(r io.Reader, bankName string, p BankProfile) BankSource {
+	return bankCSV{open: readerOpener(r, csvExts), name: bankName, profile: p}
+}
+
+// opener memanggil fn untuk setiap stream dari sebuah sumber beserta nama
+// entri zip-nya (kosong untuk file/reader biasa).
+type opener func(fn func(entry string, r io.Reader) error) error
+
+// fileOpener membuka file pada path; error parsing diberi prefix path file.
+func fileOpener(path string, exts []string) opener {
+	return func(fn func(entry string, r io.Reader) error) error {
+		err := forEachInput(path, exts, fn)
+		var pathErr *fs.PathError
+		if err != nil && !errors.As(err, &pathErr) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		return err
+	}
+}
+
+func readerOpener(r io.Reader, exts []string) opener {
+	return func(fn func(entry string, r io.Reader) error) error { return forEachReaderInput(r, exts, fn) }
+}
+
+type systemCSV struct {
+	open    opener
+	profile SystemProfile
+}
+
+func (s systemCSV) SystemTransactions() ([]model.SystemTransaction, error) {
+	var out []model.SystemTransaction
+	err := s.open(func(_ string, r io.Reader) error {
+		txs, err := readSystemCSV(r, s.profile)
+		out = append(out, txs...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type bankCSV struct {
+	open    opener
+	source  string
+	name    string
+	profile BankProfile
+	entries entryNaming
+}
+
+func (b bankCSV) BankName() string { return b.name }
+
+func (b bankCSV) BankStatements() ([]BankStatement, error) {
+	out, _, err := b.BankStatementsWithBalances()
+	return out, err
+}
+
+func (b bankCSV) BankStatementsWithBalances() ([]BankStatement, []StatementBalance, error) {
+	var out []BankStatement
+	var bals []StatementBalance
+	err := b.open(func(entry string, r io.Reader) error {
+		name, profile := b.name, b.profile
+		if entry != "" {
+			name, profile = b.entries.bank(entry, name, profile)
+		}
+		bs, bl, err := readBankCSV(r, name, profile)
+		if entry != "" {
+			withAccount(bs, bl, entryAccount(entry))
+		}
+		bl = withSource(bs, bl, name, b.source)
+		out = append(out, bs...)
+		bals = append(bals, bl...)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, bals, nil
+}
+
+// LoadBanks memuat semua BankSource secara konkuren (maksimal workers
+// sekaligus; <= 0 berarti jumlah CPU) dan menggabungkan statement per nama bank.
+// Urutan statement mengikuti urutan sources sehingga hasil deterministik.
+func LoadBanks(sources []BankSource, workers int) (map[string][]BankStatement, error) {
+	out, _, err := LoadBanksWithBalances(sources, workers)
+	return out, err
+}
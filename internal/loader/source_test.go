@@ -0,0 +1,74 @@
+package loader
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadSystemCSV(t *testing.T) {
+	content := "trxID,amount,type,transactionTime\n" +
+		"TRX-1,250000,CREDIT,2025-06-01T12:34:56Z\n" +
+		"TRX-2,125000,DEBIT,2025-06-01T15:00:00Z\n"
+	for name, r := range map[string]*bytes.Reader{
+		"plain": bytes.NewReader([]byte(content)),
+		"gzip":  bytes.NewReader(gzipBytes(t, content)),
+	} {
+		got, err := ReadSystemCSV(r, SystemProfile{})
+		if err != nil {
+			t.Fatalf("%s: ReadSystemCSV error: %v", name, err)
+		}
+		if len(got) != 2 || got[1].TrxID != "TRX-2" || got[1].Type != "DEBIT" {
+			t.Fatalf("%s: unexpected rows: %+v", name, got)
+		}
+	}
+}
+
+func TestReadBankCSV(t *testing.T) {
+	r := strings.NewReader("ref,nominal,tanggal\nBA-1,\"-75,000\",2025-06-03\n")
+	got, err := ReadBankCSV(r, "bankA", BankProfile{IDColumn: "ref", AmountColumn: "nominal", DateColumn: "tanggal"})
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if len(got) != 1 || got[0].Amount != -75000 || got[0].BankName != "bankA" || !got[0].Date.Equal(time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("unexpected rows: %+v", got)
+	}
+}
+
+// staticBank adalah BankSource in-memory untuk test.
+type staticBank struct {
+	name string
+	rows []BankStatement
+	err  error
+}
+
+func (s staticBank) BankName() string                         { return s.name }
+func (s staticBank) BankStatements() ([]BankStatement, error) { return s.rows, s.err }
+
+func TestLoadBanks(t *testing.T) {
+	sources := []BankSource{
+		NewBankCSVReader(strings.NewReader("unique_identifier,amount,date\nBA-1,100,2025-06-01\n"), "bankA", BankProfile{}),
+		staticBank{name: "bankB", rows: []BankStatement{{UniqueIdentifier: "BB-1", Amount: -50, BankName: "bankB"}}},
+		staticBank{name: "bankA", rows: []BankStatement{{UniqueIdentifier: "BA-2", Amount: 200, BankName: "bankA"}}},
+	}
+	got, err := LoadBanks(sources, 0)
+	if err != nil {
+		t.Fatalf("LoadBanks error: %v", err)
+	}
+	if len(got["bankA"]) != 2 || got["bankA"][0].UniqueIdentifier != "BA-1" || got["bankA"][1].UniqueIdentifier != "BA-2" {
+		t.Fatalf("unexpected bankA: %+v", got["bankA"])
+	}
+	if len(got["bankB"]) != 1 {
+		t.Fatalf("unexpected bankB: %+v", got["bankB"])
+	}
+
+	sources = []BankSource{
+		sources[1],
+		staticBank{name: "bankC", err: fmt.Errorf("upstream unavailable")},
+	}
+	if _, err := LoadBanks(sources, 1); err == nil || !strings.Contains(err.Error(), "bankC") {
+		t.Fatalf("expected error naming bankC, got %v", err)
+	}
+}
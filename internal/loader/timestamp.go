@@ -0,0 +1,143 @@
+package loader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Layout khusus untuk timestamp epoch (bilangan bulat UTC), dapat dipakai
+// di antara layout Go pada TimeLayouts/DateLayouts. LayoutAuto mewakili
+// seluruh autoTimeLayouts.
+const (
+	LayoutEpochSeconds = "epoch_s"
+	LayoutEpochMillis  = "epoch_ms"
+	LayoutAuto         = "auto"
+)
+
+// Layout default bila profile tidak menyebut layout.
+var (
+	defaultTimeLayouts = []string{time.RFC3339}
+	defaultDateLayouts = []string{"2006-01-02"}
+)
+
+// autoTimeLayouts dicoba berurutan untuk LayoutAuto.
+var autoTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+	"20060102",
+	LayoutEpochSeconds,
+	LayoutEpochMillis,
+}
+
+// timeParser mengurai nilai waktu dengan daftar layout; nilai tanpa zona
+// dianggap berada di loc.
+type timeParser struct {
+	layouts []string
+	epochS  bool // epoch_s tercantum
+	epochMs bool // epoch_ms tercantum
+	loc     *time.Location
+	zoned   bool // timezone diatur eksplisit
+}
+
+// newTimeParser menyiapkan parser dari daftar layout (kosong = defaults,
+// LayoutAuto diganti autoTimeLayouts) dan nama zona IANA (kosong = UTC).
+func newTimeParser(layouts, defaults []string, tz string) (timeParser, error) {
+	p := timeParser{loc: time.UTC, zoned: tz != ""}
+	if tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return p, fmt.Errorf("invalid timezone %q: %w", tz, err)
+		}
+		p.loc = loc
+	}
+	if len(layouts) == 0 {
+		layouts = defaults
+	}
+	for _, l := range layouts {
+		switch strings.TrimSpace(l) {
+		case "":
+			return p, fmt.Errorf("empty time layout")
+		case LayoutAuto:
+			p.layouts = append(p.layouts, autoTimeLayouts...)
+		default:
+			p.layouts = append(p.layouts, l)
+		}
+	}
+	for _, l := range p.layouts {
+		p.epochS = p.epochS || l == LayoutEpochSeconds
+		p.epochMs = p.epochMs || l == LayoutEpochMillis
+	}
+	return p, nil
+}
+
+// parse mengembalikan waktu dari layout pertama yang cocok. Bila epoch_s atau
+// epoch_ms tercantum, angka 10 digit lebih dulu dibaca sebagai epoch detik
+// dan 13 digit sebagai epoch milidetik.
+func (p timeParser) parse(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if isDigits(s) {
+		switch {
+		case len(s) == 10 && p.epochS:
+			return p.epoch(s, LayoutEpochSeconds)
+		case len(s) == 13 && p.epochMs:
+			return p.epoch(s, LayoutEpochMillis)
+		}
+	}
+	for _, l := range p.layouts {
+		if l == LayoutEpochSeconds || l == LayoutEpochMillis {
+			if t, err := p.epoch(s, l); err == nil {
+				return t, nil
+			}
+			continue
+		}
+		if t, err := time.ParseInLocation(l, s, p.loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no matching layout (tried %s)", strings.Join(p.layouts, ", "))
+}
+
+// date mengembalikan tanggal (00:00 UTC) dari s. Jika timezone diatur, nilai
+// berzona atau epoch dikonversi ke zona itu dulu; selain itu tanggal diambil
+// pada offset nilai itu sendiri.
+func (p timeParser) date(s string) (time.Time, error) {
+	t, err := p.parse(s)
+	if err != nil {
+		return t, err
+	}
+	if p.zoned {
+		t = t.In(p.loc)
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+func (p timeParser) epoch(s, layout string) (time.Time, error) {
+	if !isDigits(strings.TrimPrefix(s, "-")) {
+		return time.Time{}, fmt.Errorf("invalid %s value %q", layout, s)
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if layout == LayoutEpochMillis {
+		return time.UnixMilli(v).In(p.loc), nil
+	}
+	return time.Unix(v, 0).In(p.loc), nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
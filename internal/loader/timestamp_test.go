@@ -0,0 +1,125 @@
+package loader
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestReadSystemCSV_TimeFormats(t *testing.T) {
+	content := "trxID,amount,type,transactionTime\n" +
+		"TRX-1,1000,CREDIT,2025-06-01T10:00:00+07:00\n" +
+		"TRX-2,1000,CREDIT,2025-06-01 10:00:00\n" +
+		"TRX-3,1000,CREDIT,1748746800000\n" +
+		"TRX-4,1000,CREDIT,1748746800\n"
+
+	jakarta := time.FixedZone("WIB", 7*3600)
+	utc := time.Date(2025, 6, 1, 3, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name string
+		prof SystemProfile
+		want []time.Time
+	}{
+		{"auto UTC", SystemProfile{TimeLayouts: []string{LayoutAuto}}, []time.Time{utc, time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), utc, utc}},
+		{"timezone", SystemProfile{TimeLayouts: []string{LayoutAuto}, Timezone: "Asia/Jakarta"}, []time.Time{utc, time.Date(2025, 6, 1, 10, 0, 0, 0, jakarta), utc, utc}},
+	}
+	for _, c := range cases {
+		got, err := ReadSystemCSV(bytes.NewBufferString(content), c.prof)
+		if err != nil {
+			t.Fatalf("%s: ReadSystemCSV error: %v", c.name, err)
+		}
+		for i, w := range c.want {
+			if !got[i].TransactionTime.Equal(w) {
+				t.Fatalf("%s: row %d: got %v, want %v", c.name, i, got[i].TransactionTime, w)
+			}
+		}
+	}
+
+	// Tanpa layout hanya RFC3339 yang diterima.
+	if _, err := ReadSystemCSV(bytes.NewBufferString(content), SystemProfile{}); err == nil {
+		t.Fatalf("expected error for non-RFC3339 time with default layout")
+	}
+
+	// Angka 10 digit bukan epoch bila epoch_s tidak tercantum.
+	got, err := ReadSystemCSV(bytes.NewBufferString("trxID,amount,type,transactionTime\nTRX-1,1000,CREDIT,2506011000\n"), SystemProfile{TimeLayouts: []string{"0601021504"}})
+	if err != nil {
+		t.Fatalf("ReadSystemCSV error: %v", err)
+	}
+	if want := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC); !got[0].TransactionTime.Equal(want) {
+		t.Fatalf("got %v, want %v", got[0].TransactionTime, want)
+	}
+
+	// Layout eksplisit menonaktifkan deteksi otomatis.
+	prof := SystemProfile{TimeLayouts: []string{"02/01/2006 15:04", LayoutEpochMillis}, Timezone: "Asia/Jakarta"}
+	got, err = ReadSystemCSV(bytes.NewBufferString("trxID,amount,type,transactionTime\n"+
+		"TRX-1,1000,CREDIT,01/06/2025 10:00\n"+
+		"TRX-2,1000,CREDIT,1748746800000\n"), prof)
+	if err != nil {
+		t.Fatalf("ReadSystemCSV error: %v", err)
+	}
+	for i := range got {
+		if !got[i].TransactionTime.Equal(utc) {
+			t.Fatalf("row %d: got %v, want %v", i, got[i].TransactionTime, utc)
+		}
+	}
+	if _, err := ReadSystemCSV(bytes.NewBufferString("trxID,amount,type,transactionTime\nTRX-1,1000,CREDIT,2025-06-01T10:00:00Z\n"), prof); err == nil {
+		t.Fatalf("expected error for layout outside time_layouts")
+	}
+
+	bad := []SystemProfile{
+		{Timezone: "Mars/Olympus"},
+		{TimeLayouts: []string{" "}},
+	}
+	for _, p := range bad {
+		if err := p.Validate(); err == nil {
+			t.Fatalf("expected error for %+v", p)
+		}
+	}
+}
+
+func TestReadBankCSV_DateFormats(t *testing.T) {
+	content := "unique_identifier,amount,date\n" +
+		"BA-1,1000,2025-06-01\n" +
+		"BA-2,1000,2025-06-01 23:30:00\n" +
+		"BA-3,1000,2025-06-01T20:00:00Z\n" +
+		"BA-4,1000,1748808000000\n"
+
+	day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		name string
+		prof BankProfile
+		want []time.Time
+	}{
+		{"auto", BankProfile{DateLayout: LayoutAuto}, []time.Time{day(1), day(1), day(1), day(1)}},
+		// 20:00Z dan epoch 2025-06-01T20:00Z sudah 2 Juni di Jakarta.
+		{"timezone", BankProfile{DateLayout: LayoutAuto, Timezone: "Asia/Jakarta"}, []time.Time{day(1), day(1), day(2), day(2)}},
+	}
+	for _, c := range cases {
+		got, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", c.prof)
+		if err != nil {
+			t.Fatalf("%s: ReadBankCSV error: %v", c.name, err)
+		}
+		for i, w := range c.want {
+			if !got[i].Date.Equal(w) {
+				t.Fatalf("%s: row %d: got %v, want %v", c.name, i, got[i].Date, w)
+			}
+		}
+	}
+
+	// Tanpa layout hanya 2006-01-02 yang diterima.
+	if _, err := ReadBankCSV(bytes.NewBufferString(content), "bankA", BankProfile{}); err == nil {
+		t.Fatalf("expected error for non-date value with default layout")
+	}
+
+	prof := BankProfile{DateLayout: "02/01/2006", DateLayouts: []string{"2006-01-02"}}
+	got, err := ReadBankCSV(bytes.NewBufferString("unique_identifier,amount,date\nBA-1,1000,02/06/2025\nBA-2,1000,2025-06-03\n"), "bankA", prof)
+	if err != nil {
+		t.Fatalf("ReadBankCSV error: %v", err)
+	}
+	if !got[0].Date.Equal(day(2)) || !got[1].Date.Equal(day(3)) {
+		t.Fatalf("unexpected dates: %v, %v", got[0].Date, got[1].Date)
+	}
+	if err := (BankProfile{Timezone: "WIB"}).Validate(); err == nil {
+		t.Fatalf("expected error for invalid timezone")
+	}
+}
@@ -0,0 +1,91 @@
+package loader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tipe transaksi sistem kanonik hasil LoadSystemCSV.
+const (
+	TypeCredit = "CREDIT"
+	TypeDebit  = "DEBIT"
+)
+
+// Perlakuan nilai type yang tidak dikenal (SystemProfile.UnknownTypes).
+const (
+	UnknownTypeReject = "reject" // default: file ditolak
+	UnknownTypeReport = "report" // nilai mentah dipertahankan; engine melaporkan transaksinya
+)
+
+// defaultTypes adalah kosakata type bawaan; SystemProfile.Types menambah
+// atau menimpanya.
+var defaultTypes = map[string]string{
+	"CREDIT": TypeCredit,
+	"CR":     TypeCredit,
+	"DEBIT":  TypeDebit,
+	"DR":     TypeDebit,
+}
+
+// CanonicalType memetakan raw dengan kosakata bawaan (tanpa membedakan huruf
+// besar/kecil) ke TypeCredit atau TypeDebit; ok false bila tidak dikenal.
+func CanonicalType(raw string) (t string, ok bool) {
+	t, ok = defaultTypes[strings.ToUpper(strings.TrimSpace(raw))]
+	return t, ok
+}
+
+// typeMapper adalah kosakata type yang sudah divalidasi.
+type typeMapper struct {
+	types  map[string]string // key huruf besar -> TypeCredit/TypeDebit
+	report bool
+}
+
+func compileTypes(types map[string]string, unknown string) (typeMapper, error) {
+	m := typeMapper{types: map[string]string{}}
+	switch unknown {
+	case "", UnknownTypeReject:
+	case UnknownTypeReport:
+		m.report = true
+	default:
+		return m, fmt.Errorf("unknown_types must be %s or %s, got %q", UnknownTypeReject, UnknownTypeReport, unknown)
+	}
+	for k, v := range defaultTypes {
+		m.types[k] = v
+	}
+	for k, v := range types {
+		key := strings.ToUpper(strings.TrimSpace(k))
+		if key == "" {
+			return m, fmt.Errorf("types: empty type value")
+		}
+		switch strings.ToUpper(strings.TrimSpace(v)) {
+		case TypeCredit:
+			m.types[key] = TypeCredit
+		case TypeDebit:
+			m.types[key] = TypeDebit
+		default:
+			return m, fmt.Errorf("types[%q]: must map to %s or %s, got %q", k, TypeCredit, TypeDebit, v)
+		}
+	}
+	return m, nil
+}
+
+// canonical mengembalikan TypeCredit atau TypeDebit untuk raw. Nilai yang
+// tidak dikenal ditolak, atau dikembalikan apa adanya pada mode report.
+func (m typeMapper) canonical(raw string) (string, error) {
+	if t, ok := m.types[strings.ToUpper(strings.TrimSpace(raw))]; ok {
+		return t, nil
+	}
+	if m.report {
+		return raw, nil
+	}
+	return "", fmt.Errorf("unknown transaction type %q (allowed: %s)", raw, m.allowed())
+}
+
+func (m typeMapper) allowed() string {
+	keys := make([]string, 0, len(m.types))
+	for k := range m.types {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ", ")
+}
@@ -6,8 +6,10 @@ import "time"
 type SystemTransaction struct {
     TrxID           string
     Amount          int64  // asumsi: satuan Rupiah (tanpa desimal)
-    Type            string // "DEBIT" atau "CREDIT"
+    Type            string // "DEBIT" atau "CREDIT"; selain itu dilaporkan sebagai UnknownTypeTransaction
     TransactionTime time.Time
+    Account         string // bank/rekening tujuan (nama bank atau nomor rekening); kosong = bank mana pun
+    Description     string // narasi transaksi, mis. nama peminjam atau nomor pinjaman
 }
 
 // BankStatement merepresentasikan transaksi dari bank (per file/bank).
@@ -20,9 +22,11 @@ type BankStatement struct {
 
 // NormalizedRecord untuk matching per tanggal + tanda amount.
 type NormalizedRecord struct {
-    ID     string
-    Date   time.Time // diseragamkan ke tanggal
-    Amount int64     // signed
+    ID          string
+    Date        time.Time // diseragamkan ke tanggal
+    Amount      int64     // signed
+    Account     string    `json:",omitempty"` // routing sistem atau rekening bank
+    Description string    `json:",omitempty"`
 }
 
 // MatchedPair hasil pasangan matched system vs bank.
@@ -33,24 +37,140 @@ type MatchedPair struct {
     Date         string
     SystemAmount int64
     BankAmount   int64
-    Discrepancy  int64 // |SystemAmount - BankAmount|
+    Discrepancy  int64  // |SystemAmount - BankAmount|
+    Category     string // MatchExact, MatchWithFee, atau MatchDiscrepancy
+    Fee          int64  `json:",omitempty"` // biaya bank (SystemAmount - BankAmount) untuk MatchWithFee
+    SignMismatch bool   `json:",omitempty"` // dipasangkan lintas tanda (mode sign-agnostic)
 }
 
+// Kategori MatchedPair.
+const (
+    MatchExact       = "exact"                   // tanpa selisih
+    MatchWithFee     = "matched_with_fee"        // selisih dijelaskan biaya bank yang diketahui
+    MatchDiscrepancy = "unexplained_discrepancy" // selisih dalam toleransi tanpa penjelasan
+)
+
 // Result ringkasan dan detail rekonsiliasi.
 type Result struct {
     Summary Summary `json:"summary"`
     Details Details `json:"details"`
+    // BalanceReconciliation hanya diisi jika mode rekonsiliasi saldo aktif.
+    BalanceReconciliation *BalanceReconciliation `json:"balance_reconciliation,omitempty"`
 }
 
 type Summary struct {
-    TotalProcessed     int   `json:"total_processed"`
-    TotalMatched       int   `json:"total_matched"`
-    TotalUnmatched     int   `json:"total_unmatched"`
-    TotalDiscrepancies int64 `json:"total_discrepancies"`
+    TotalProcessed                int   `json:"total_processed"`
+    TotalMatched                  int   `json:"total_matched"`
+    TotalUnmatched                int   `json:"total_unmatched"`
+    TotalDiscrepancies            int64 `json:"total_discrepancies"`
+    TotalBalanceBreaks            int   `json:"total_balance_breaks"`
+    TotalMisrouted                int   `json:"total_misrouted"`
+    TotalMatchedWithFee           int   `json:"total_matched_with_fee"`
+    TotalFees                     int64 `json:"total_fees"`
+    TotalUnexplainedDiscrepancies int   `json:"total_unexplained_discrepancies"`
+    TotalReversals                int   `json:"total_reversals"`
+    TotalSignMismatches           int   `json:"total_sign_mismatches"`
+    TotalUnknownTypes             int   `json:"total_unknown_types"`
 }
 
 type Details struct {
     Matched              []MatchedPair            `json:"matched"`
     UnmatchedSystem      []NormalizedRecord       `json:"unmatched_system"`
     UnmatchedBankByGroup map[string][]NormalizedRecord `json:"unmatched_bank_by_group"`
-}
\ No newline at end of file
+    BalanceBreaks        []BalanceBreak           `json:"balance_breaks"`
+    Misrouted            []MisroutedMatch         `json:"misrouted"`
+    // UnexplainedDiscrepancies adalah pasangan matched berkategori
+    // MatchDiscrepancy (juga tercantum di Matched).
+    UnexplainedDiscrepancies []MatchedPair            `json:"unexplained_discrepancies"`
+    Reversals                []Reversal               `json:"reversals"`
+    SignInversions           []SignInversion          `json:"sign_inversions"`
+    UnknownTypes             []UnknownTypeTransaction `json:"unknown_types"`
+}
+
+// UnknownTypeTransaction transaksi sistem dengan type selain CREDIT/DEBIT;
+// tidak ikut matching karena tandanya tidak diketahui.
+type UnknownTypeTransaction struct {
+    SystemID string `json:"system_id"`
+    Type     string `json:"type"`
+    Amount   int64  `json:"amount"`
+    Date     string `json:"date"`
+}
+
+// SignInversion file bank yang tanda amount-nya tampak terbalik: dari
+// Compared record yang amount absolutnya ada di sistem pada tanggal yang
+// sama, Inverted hanya cocok dengan tanda berlawanan.
+type SignInversion struct {
+    BankName string  `json:"bank_name"`
+    Source   string  `json:"source"` // path file; kosong untuk sumber reader
+    Compared int     `json:"compared"`
+    Inverted int     `json:"inverted"`
+    Ratio    float64 `json:"ratio"`
+}
+
+// Reversal pasangan record dalam satu sumber yang saling membatalkan (amount
+// sama, tanda berlawanan); keduanya dikeluarkan dari matching.
+type Reversal struct {
+    Source       string `json:"source"` // ReversalSourceSystem atau nama bank
+    OriginalID   string `json:"original_id"`
+    ReversalID   string `json:"reversal_id"`
+    OriginalDate string `json:"original_date"`
+    ReversalDate string `json:"reversal_date"`
+    Amount       int64  `json:"amount"` // amount bertanda record asli
+    Linked       bool   `json:"linked"` // kedua record saling merujuk (referensi/ID)
+}
+
+// ReversalSourceSystem adalah Reversal.Source untuk transaksi sistem.
+const ReversalSourceSystem = "system"
+
+// MisroutedMatch transaksi sistem yang tidak matched di rekening tujuannya
+// tetapi cocok dengan statement unmatched bank lain; hanya dilaporkan,
+// tidak dihitung sebagai matched.
+type MisroutedMatch struct {
+    SystemID        string `json:"system_id"`
+    ExpectedAccount string `json:"expected_account"`
+    BankID          string `json:"bank_id"`
+    BankName        string `json:"bank_name"`
+    BankAccount     string `json:"bank_account,omitempty"`
+    Date            string `json:"date"`
+    SystemAmount    int64  `json:"system_amount"`
+    BankAmount      int64  `json:"bank_amount"`
+}
+
+// BalanceBreak statement bank yang saldo awal + total mutasi tidak sama
+// dengan saldo akhir; hasil matching bank tersebut tidak dapat dipercaya.
+type BalanceBreak struct {
+    BankName   string `json:"bank_name"`
+    Source     string `json:"source,omitempty"`
+    Statement  string `json:"statement,omitempty"`
+    Account    string `json:"account,omitempty"`
+    Date       string `json:"date"`
+    Opening    int64  `json:"opening"`
+    Closing    int64  `json:"closing"`
+    Total      int64  `json:"total"`      // jumlah mutasi pada statement
+    Difference int64  `json:"difference"` // Opening + Total - Closing
+}
+
+// BalanceReconciliation membuktikan selisih mutasi bersih sistem vs bank per
+// tanggal dengan item yang tidak matched.
+type BalanceReconciliation struct {
+    ByBank       []BalanceLine `json:"by_bank"`      // per bank & tanggal
+    Unattributed []BalanceLine `json:"unattributed"` // per tanggal, sistem yang tidak dapat diatribusikan ke bank
+    Totals       []BalanceLine `json:"totals"`       // per tanggal, semua bank + sistem
+    Unexplained  int           `json:"unexplained"`  // jumlah baris dengan residue != 0
+}
+
+// BalanceLine satu baris rekonsiliasi saldo. Difference = SystemTotal - BankTotal,
+// dijelaskan oleh MatchedDifference + UnmatchedSystem - UnmatchedBank; sisanya
+// adalah Residue.
+type BalanceLine struct {
+    BankName          string `json:"bank_name,omitempty"` // kosong pada Totals
+    Date              string `json:"date"`
+    SystemTotal       int64  `json:"system_total"`
+    BankTotal         int64  `json:"bank_total"`
+    Difference        int64  `json:"difference"`
+    MatchedDifference int64  `json:"matched_difference"` // selisih bersih pasangan matched
+    UnmatchedSystem   int64  `json:"unmatched_system"`
+    UnmatchedBank     int64  `json:"unmatched_bank"`
+    Residue           int64  `json:"residue"`
+    Explained         bool   `json:"explained"`
+}
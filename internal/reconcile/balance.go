@@ -0,0 +1,44 @@
+package reconcile
+
+import (
+	"sort"
+	"time"
+
+	"amartha/internal/loader"
+	"amartha/internal/model"
+)
+
+// verifyBalances memeriksa saldo awal + total mutasi = saldo akhir untuk
+// setiap statement yang tanggal saldo akhirnya berada dalam rentang.
+// Hasil diurutkan berdasarkan bank, tanggal, lalu sumber.
+func verifyBalances(bals []loader.StatementBalance, start, end time.Time) []model.BalanceBreak {
+	breaks := []model.BalanceBreak{}
+	for _, b := range bals {
+		d := time.Date(b.Date.Year(), b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if d.Before(start) || d.After(end) || b.Difference() == 0 {
+			continue
+		}
+		breaks = append(breaks, model.BalanceBreak{
+			BankName:   b.BankName,
+			Source:     b.Source,
+			Statement:  b.Statement,
+			Account:    b.Account,
+			Date:       d.Format("2006-01-02"),
+			Opening:    b.Opening,
+			Closing:    b.Closing,
+			Total:      b.Total,
+			Difference: b.Difference(),
+		})
+	}
+	sort.SliceStable(breaks, func(i, j int) bool {
+		a, b := breaks[i], breaks[j]
+		if a.BankName != b.BankName {
+			return a.BankName < b.BankName
+		}
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.Source < b.Source
+	})
+	return breaks
+}
@@ -2,7 +2,6 @@ package reconcile
 
 import (
 	"sort"
-	"strings"
 	"time"
 
 	"amartha/internal/loader"
@@ -14,43 +13,149 @@ const discrepancyTolerance int64 = 5000
 // bankRec adalah representasi record bank yang disertai nama bank untuk pelaporan.
 type bankRec struct {
 	model.NormalizedRecord
-	BankName string
+	BankName  string
+	Reference string
+	Source    string // path file asal statement
+}
+
+// routesTo melaporkan apakah record bank milik bank atau rekening account.
+func (b bankRec) routesTo(account string) bool {
+	return b.BankName == account || (b.Account != "" && b.Account == account)
 }
 
 // Reconcile melakukan rekonsiliasi antara transaksi sistem dan bank dalam rentang tanggal.
 // Strategi matching: per tanggal dan tanda amount; pasangan dibentuk dengan
 // mengurutkan amount dan dipasangkan berurutan (minimalkan total selisih absolut).
 func Reconcile(sys []model.SystemTransaction, banks map[string][]loader.BankStatement, start, end time.Time) (model.Result, error) {
-	// Filter dan normalisasi sistem.
-	var sysPos, sysNeg []model.NormalizedRecord // pos: credit, neg: debit
+	return ReconcileWithOptions(sys, banks, start, end, DefaultOptions())
+}
+
+// ReconcileWithOptions sama dengan Reconcile, dengan toleransi, strategi, zona
+// waktu, dan opsi matching lain yang diatur lewat opts.
+func ReconcileWithOptions(sys []model.SystemTransaction, banks map[string][]loader.BankStatement, start, end time.Time, opts Options) (model.Result, error) {
+	if err := opts.Validate(); err != nil {
+		return model.Result{}, err
+	}
+	loc := opts.location()
+
+	// Verifikasi saldo statement bank lebih dulu.
+	breaks := verifyBalances(opts.Balances, start, end)
+
+	// Filter dan normalisasi sistem. Type dikanonikkan dengan kosakata bawaan
+	// loader (mis. "cr", "DR") agar transaksi yang tidak lewat loader tetap
+	// dikenali; type lain tidak ikut matching karena tandanya tidak diketahui.
+	var sysRecs []model.NormalizedRecord
+	unknownTypes := []model.UnknownTypeTransaction{}
 	for _, s := range sys {
-		d := s.TransactionTime.In(time.UTC)
+		d := s.TransactionTime.In(loc)
 		dateOnly := time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
 		if dateOnly.Before(start) || dateOnly.After(end) {
 			continue
 		}
-		signed := s.Amount
-		if strings.EqualFold(s.Type, "DEBIT") {
-			signed = -signed
-		}
-		rec := model.NormalizedRecord{ID: s.TrxID, Date: dateOnly, Amount: signed}
-		if signed >= 0 {
-			sysPos = append(sysPos, rec)
-		} else {
-			sysNeg = append(sysNeg, rec)
+		var signed int64
+		switch t, _ := loader.CanonicalType(s.Type); t {
+		case loader.TypeCredit:
+			signed = s.Amount
+		case loader.TypeDebit:
+			signed = -s.Amount
+		default:
+			unknownTypes = append(unknownTypes, model.UnknownTypeTransaction{
+				SystemID: s.TrxID,
+				Type:     s.Type,
+				Amount:   s.Amount,
+				Date:     dateOnly.Format("2006-01-02"),
+			})
+			continue
 		}
+		sysRecs = append(sysRecs, model.NormalizedRecord{ID: s.TrxID, Date: dateOnly, Amount: signed, Account: s.Account, Description: s.Description})
 	}
 
 	// Filter dan normalisasi bank.
-	var bankPos, bankNeg []bankRec
-	for bankName, list := range banks {
-		for _, b := range list {
+	bankNames := make([]string, 0, len(banks))
+	for bankName := range banks {
+		bankNames = append(bankNames, bankName)
+	}
+	sort.Strings(bankNames) // urutan iterasi map tidak deterministik
+	bankRecs := make(map[string][]bankRec, len(banks))
+	for _, bankName := range bankNames {
+		for _, b := range banks[bankName] {
 			d := time.Date(b.Date.Year(), b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, time.UTC)
 			if d.Before(start) || d.After(end) {
 				continue
 			}
-			br := bankRec{NormalizedRecord: model.NormalizedRecord{ID: b.UniqueIdentifier, Date: d, Amount: b.Amount}, BankName: bankName}
-			if b.Amount >= 0 {
+			bankRecs[bankName] = append(bankRecs[bankName], bankRec{
+				NormalizedRecord: model.NormalizedRecord{ID: b.UniqueIdentifier, Date: d, Amount: b.Amount, Account: b.Account, Description: b.Description},
+				BankName:         bankName,
+				Reference:        b.Reference,
+				Source:           b.Source,
+			})
+		}
+	}
+
+	// Pembatalan dalam satu sumber dikeluarkan sebelum matching. Calon
+	// pasangan di sumber lain dihitung dari record sebelum pre-pass.
+	reversals := []model.Reversal{}
+	var reversedSys []model.NormalizedRecord
+	reversedBank := map[string][]model.NormalizedRecord{}
+	if opts.Reversals {
+		bankIx := counterpartIndex{}
+		for _, bankName := range bankNames {
+			for _, r := range bankRecs[bankName] {
+				bankIx.add(r.Date, r.Amount, opts.toleranceFor(bankName))
+			}
+		}
+		items := make([]reversalItem, len(sysRecs))
+		for i, r := range sysRecs {
+			items[i] = reversalItem{NormalizedRecord: r}
+		}
+		rev, found := findReversals(model.ReversalSourceSystem, items, bankIx, opts)
+		reversals = append(reversals, found...)
+		kept := sysRecs[:0:0]
+		for i, r := range sysRecs {
+			if rev[i] {
+				reversedSys = append(reversedSys, r)
+			} else {
+				kept = append(kept, r)
+			}
+		}
+
+		for _, bankName := range bankNames {
+			sysIx := counterpartIndex{}
+			for _, r := range sysRecs {
+				sysIx.add(r.Date, r.Amount, opts.toleranceFor(bankName))
+			}
+			recs := bankRecs[bankName]
+			items := make([]reversalItem, len(recs))
+			for i, r := range recs {
+				items[i] = reversalItem{NormalizedRecord: r.NormalizedRecord, Reference: r.Reference}
+			}
+			rev, found := findReversals(bankName, items, sysIx, opts)
+			reversals = append(reversals, found...)
+			keptBank := recs[:0:0]
+			for i, r := range recs {
+				if rev[i] {
+					reversedBank[bankName] = append(reversedBank[bankName], r.NormalizedRecord)
+				} else {
+					keptBank = append(keptBank, r)
+				}
+			}
+			bankRecs[bankName] = keptBank
+		}
+		sysRecs = kept
+	}
+
+	var sysPos, sysNeg []model.NormalizedRecord // pos: credit, neg: debit
+	for _, rec := range sysRecs {
+		if rec.Amount >= 0 {
+			sysPos = append(sysPos, rec)
+		} else {
+			sysNeg = append(sysNeg, rec)
+		}
+	}
+	var bankPos, bankNeg []bankRec
+	for _, bankName := range bankNames {
+		for _, br := range bankRecs[bankName] {
+			if br.Amount >= 0 {
 				bankPos = append(bankPos, br)
 			} else {
 				bankNeg = append(bankNeg, br)
@@ -58,57 +163,117 @@ func Reconcile(sys []model.SystemTransaction, banks map[string][]loader.BankStat
 		}
 	}
 
+	inversions := detectSignInversions(append(append([]model.NormalizedRecord{}, sysPos...), sysNeg...), append(append([]bankRec{}, bankPos...), bankNeg...))
+
 	matched := []model.MatchedPair{}
 	unmatchedSys := []model.NormalizedRecord{}
 	unmatchedBankByGroup := map[string][]model.NormalizedRecord{}
 
 	// Proses per tanda dan per tanggal.
-	matchedPos, umSysPos, umBankPos := matchByDateAndAmount(sysPos, bankPos)
-	matchedNeg, umSysNeg, umBankNeg := matchByDateAndAmount(sysNeg, bankNeg)
+	matchedPos, umSysPos, umBankPos, misPos := matchByDateAndAmount(sysPos, bankPos, opts)
+	matchedNeg, umSysNeg, umBankNeg, misNeg := matchByDateAndAmount(sysNeg, bankNeg, opts)
+	misrouted := append(append([]model.MisroutedMatch{}, misPos...), misNeg...)
 
 	matched = append(matched, matchedPos...)
 	matched = append(matched, matchedNeg...)
+
+	// Mode tanpa tanda: sisa record dipasangkan lintas tanda.
+	var signMismatches int
+	if opts.SignAgnostic {
+		mPos, sPos, bNeg := matchFlipped(umSysPos, umBankNeg, opts)
+		mNeg, sNeg, bPos := matchFlipped(umSysNeg, umBankPos, opts)
+		umSysPos, umBankNeg, umSysNeg, umBankPos = sPos, bNeg, sNeg, bPos
+		flippedPairs := append(mPos, mNeg...)
+		matched = append(matched, flippedPairs...)
+		misrouted = dropMatchedMisrouted(misrouted, flippedPairs)
+		signMismatches = len(flippedPairs)
+	}
+
 	unmatchedSys = append(unmatchedSys, umSysPos...)
 	unmatchedSys = append(unmatchedSys, umSysNeg...)
-	for bankName, recs := range umBankPos {
-		unmatchedBankByGroup[bankName] = append(unmatchedBankByGroup[bankName], recs...)
-	}
-	for bankName, recs := range umBankNeg {
-		unmatchedBankByGroup[bankName] = append(unmatchedBankByGroup[bankName], recs...)
+	for _, b := range append(umBankPos, umBankNeg...) {
+		unmatchedBankByGroup[b.BankName] = append(unmatchedBankByGroup[b.BankName], b.NormalizedRecord)
 	}
 
 	// Ringkasan.
-	totalProcessed := len(sysPos) + len(sysNeg) + len(bankPos) + len(bankNeg)
-	var totalDiscrepancies int64
-	for _, m := range matched {
-		totalDiscrepancies += abs64(m.SystemAmount - m.BankAmount)
+	totalProcessed := len(sysPos) + len(sysNeg) + len(bankPos) + len(bankNeg) + 2*len(reversals) + len(unknownTypes)
+	var totalDiscrepancies, totalFees int64
+	var withFee int
+	unexplained := []model.MatchedPair{}
+	for i := range matched {
+		m := &matched[i]
+		totalDiscrepancies += m.Discrepancy
+		opts.classifyMatch(m)
+		switch m.Category {
+		case model.MatchWithFee:
+			withFee++
+			totalFees += m.Fee
+		case model.MatchDiscrepancy:
+			unexplained = append(unexplained, *m)
+		}
 	}
 	totalUnmatched := len(unmatchedSys)
 	for _, recs := range unmatchedBankByGroup {
 		totalUnmatched += len(recs)
 	}
 
+	var balanceRecon *model.BalanceReconciliation
+	if opts.BalanceReconciliation {
+		// Record pembatalan tidak berpasangan dengan sisi lain sehingga
+		// diperlakukan sebagai unmatched pada rekonsiliasi saldo.
+		sysAll := append(append(append([]model.NormalizedRecord{}, sysPos...), sysNeg...), reversedSys...)
+		bankAll := append(append([]bankRec{}, bankPos...), bankNeg...)
+		umBank := map[string][]model.NormalizedRecord{}
+		for bankName, recs := range unmatchedBankByGroup {
+			umBank[bankName] = append(umBank[bankName], recs...)
+		}
+		for bankName, recs := range reversedBank {
+			for _, r := range recs {
+				bankAll = append(bankAll, bankRec{NormalizedRecord: r, BankName: bankName})
+			}
+			umBank[bankName] = append(umBank[bankName], recs...)
+		}
+		umSys := append(append([]model.NormalizedRecord{}, unmatchedSys...), reversedSys...)
+		balanceRecon = reconcileBalances(sysAll, bankAll, matched, umSys, umBank, accountBanks(banks, bankNames), opts.Balances, start, end)
+	}
+
 	return model.Result{
 		Summary: model.Summary{
-			TotalProcessed:     totalProcessed,
-			TotalMatched:       len(matched),
-			TotalUnmatched:     totalUnmatched,
-			TotalDiscrepancies: totalDiscrepancies,
+			TotalProcessed:                totalProcessed,
+			TotalMatched:                  len(matched),
+			TotalUnmatched:                totalUnmatched,
+			TotalDiscrepancies:            totalDiscrepancies,
+			TotalBalanceBreaks:            len(breaks),
+			TotalMisrouted:                len(misrouted),
+			TotalMatchedWithFee:           withFee,
+			TotalFees:                     totalFees,
+			TotalUnexplainedDiscrepancies: len(unexplained),
+			TotalReversals:                len(reversals),
+			TotalSignMismatches:           signMismatches,
+			TotalUnknownTypes:             len(unknownTypes),
 		},
 		Details: model.Details{
-			Matched:              matched,
-			UnmatchedSystem:      unmatchedSys,
-			UnmatchedBankByGroup: unmatchedBankByGroup,
+			Matched:                  matched,
+			UnmatchedSystem:          unmatchedSys,
+			UnmatchedBankByGroup:     unmatchedBankByGroup,
+			BalanceBreaks:            breaks,
+			Misrouted:                misrouted,
+			UnexplainedDiscrepancies: unexplained,
+			Reversals:                reversals,
+			SignInversions:           inversions,
+			UnknownTypes:             unknownTypes,
 		},
+		BalanceReconciliation: balanceRecon,
 	}, nil
 }
 
 // matchByDateAndAmount melakukan pairing per tanggal yang sama, dengan mengurutkan amount
 // untuk meminimalkan total selisih absolut. Bank rec menyimpan nama bank untuk pelaporan.
-func matchByDateAndAmount(sys []model.NormalizedRecord, bank []bankRec) (
+func matchByDateAndAmount(sys []model.NormalizedRecord, bank []bankRec, opts Options) (
 	[]model.MatchedPair,
 	[]model.NormalizedRecord,
-	map[string][]model.NormalizedRecord,
+	[]bankRec,
+	[]model.MisroutedMatch,
 ) {
 	sysByDate := groupByDateSys(sys)
 	bankByDate := groupByDateBank(bank)
@@ -116,18 +281,18 @@ func matchByDateAndAmount(sys []model.NormalizedRecord, bank []bankRec) (
 
 	matched := []model.MatchedPair{}
 	unmatchedSys := []model.NormalizedRecord{}
-	unmatchedBank := map[string][]model.NormalizedRecord{}
+	var unmatchedBank []bankRec
+	var misrouted []model.MisroutedMatch
 
 	for _, d := range ds {
-		m, umS, umB := pairForDate(d, sysByDate[d], bankByDate[d])
+		m, umS, umB, mr := pairForDate(d, sysByDate[d], bankByDate[d], opts)
 		matched = append(matched, m...)
 		unmatchedSys = append(unmatchedSys, umS...)
-		for k, v := range umB {
-			unmatchedBank[k] = append(unmatchedBank[k], v...)
-		}
+		unmatchedBank = append(unmatchedBank, umB...)
+		misrouted = append(misrouted, mr...)
 	}
 
-	return matched, unmatchedSys, unmatchedBank
+	return matched, unmatchedSys, unmatchedBank, misrouted
 }
 
 // abs64 mengembalikan nilai absolut dari bilangan bertanda int64.
@@ -175,27 +340,83 @@ func collectSortedDates(sysByDate map[time.Time][]model.NormalizedRecord, bankBy
 }
 
 // pairForDate mencocokkan record sistem dan bank untuk satu tanggal tertentu.
-// Daftar diurutkan berdasarkan amount, lalu dipasangkan dengan two-pointer
-// menggunakan toleransi selisih untuk menentukan pasangan matched dan elemen unmatched.
-func pairForDate(d time.Time, sList []model.NormalizedRecord, bList []bankRec) (
+// Record sistem yang memiliki Account hanya dipasangkan dengan statement bank
+// atau rekening tersebut; record sistem tanpa Account dipasangkan dengan
+// statement yang tersisa. Record sistem ber-Account yang tetap unmatched
+// dicocokkan dengan statement unmatched bank lain untuk dilaporkan sebagai
+// misrouted.
+func pairForDate(d time.Time, sList []model.NormalizedRecord, bList []bankRec, opts Options) (
 	[]model.MatchedPair,
 	[]model.NormalizedRecord,
-	map[string][]model.NormalizedRecord,
+	[]bankRec,
+	[]model.MisroutedMatch,
 ) {
+	routed := map[string][]model.NormalizedRecord{}
+	var accounts []string
+	var unrouted []model.NormalizedRecord
+	for _, s := range sList {
+		if s.Account == "" {
+			unrouted = append(unrouted, s)
+			continue
+		}
+		if _, ok := routed[s.Account]; !ok {
+			accounts = append(accounts, s.Account)
+		}
+		routed[s.Account] = append(routed[s.Account], s)
+	}
+	sort.Strings(accounts)
+
 	matched := []model.MatchedPair{}
 	unmatchedSys := []model.NormalizedRecord{}
-	unmatchedBank := map[string][]model.NormalizedRecord{}
+	pool := bList
+	for _, acct := range accounts {
+		var candidates, rest []bankRec
+		for _, b := range pool {
+			if b.routesTo(acct) {
+				candidates = append(candidates, b)
+			} else {
+				rest = append(rest, b)
+			}
+		}
+		m, umS, umB := pairSorted(d, routed[acct], candidates, opts)
+		matched = append(matched, m...)
+		unmatchedSys = append(unmatchedSys, umS...)
+		pool = append(rest, umB...)
+	}
+	routedUnmatched := unmatchedSys
+	m, umS, umB := pairSorted(d, unrouted, pool, opts)
+	matched = append(matched, m...)
+	unmatchedSys = append(unmatchedSys, umS...)
+	return matched, unmatchedSys, umB, findMisrouted(d, routedUnmatched, umB, opts)
+}
+
+// pairSorted mengurutkan kedua daftar berdasarkan amount, lalu memasangkannya
+// dengan two-pointer menggunakan toleransi selisih (per bank) untuk
+// menentukan pasangan matched dan elemen unmatched.
+func pairSorted(d time.Time, sList []model.NormalizedRecord, bList []bankRec, opts Options) (
+	[]model.MatchedPair,
+	[]model.NormalizedRecord,
+	[]bankRec,
+) {
+	matched := []model.MatchedPair{}
+	unmatchedSys := []model.NormalizedRecord{}
+	var unmatchedBank []bankRec
 
-	// mengurutkan amount
-	sort.Slice(sList, func(i, j int) bool { return sList[i].Amount < sList[j].Amount })
-	sort.Slice(bList, func(i, j int) bool { return bList[i].Amount < bList[j].Amount })
+	// mengurutkan amount; ID (dan nama bank) sebagai tie-breaker agar hasil
+	// deterministik untuk record ber-amount sama.
+	sort.SliceStable(sList, func(i, j int) bool { return lessSys(sList[i], sList[j]) })
+	sort.SliceStable(bList, func(i, j int) bool { return lessBank(bList[i], bList[j]) })
 
 	i, j := 0, 0
 	for i < len(sList) && j < len(bList) {
+		diff := abs64(sList[i].Amount - bList[j].Amount)
+		if opts.DescriptionTieBreak && diff <= opts.toleranceFor(bList[j].BankName) {
+			chooseByDescription(sList[i:], bList[j:], opts)
+			diff = abs64(sList[i].Amount - bList[j].Amount)
+		}
 		s := sList[i]
 		b := bList[j]
-		diff := abs64(s.Amount - b.Amount)
-		if diff <= discrepancyTolerance {
+		if diff <= opts.toleranceFor(b.BankName) {
 			matched = append(matched, model.MatchedPair{
 				SystemID:     s.ID,
 				BankID:       b.ID,
@@ -211,19 +432,103 @@ func pairForDate(d time.Time, sList []model.NormalizedRecord, bList []bankRec) (
 			unmatchedSys = append(unmatchedSys, s)
 			i++
 		} else {
-			unmatchedBank[b.BankName] = append(unmatchedBank[b.BankName], b.NormalizedRecord)
+			unmatchedBank = append(unmatchedBank, b)
 			j++
 		}
 	}
 	if i < len(sList) {
 		unmatchedSys = append(unmatchedSys, sList[i:]...)
 	}
-	if j < len(bList) {
-		for ; j < len(bList); j++ {
-			b := bList[j]
-			unmatchedBank[b.BankName] = append(unmatchedBank[b.BankName], b.NormalizedRecord)
+	unmatchedBank = append(unmatchedBank, bList[j:]...)
+
+	return matched, unmatchedSys, unmatchedBank
+}
+
+func lessSys(a, b model.NormalizedRecord) bool {
+	if a.Amount != b.Amount {
+		return a.Amount < b.Amount
+	}
+	return a.ID < b.ID
+}
+
+func lessBank(a, b bankRec) bool {
+	if a.Amount != b.Amount {
+		return a.Amount < b.Amount
+	}
+	if a.BankName != b.BankName {
+		return a.BankName < b.BankName
+	}
+	return a.ID < b.ID
+}
+
+// chooseByDescription memilih pasangan dengan narasi paling mirip di antara
+// kandidat yang ambigu terhadap pasangan terdepan (sList[0], bList[0]): record
+// sistem yang masih dalam toleransi bList[0] dan record bank yang masih dalam
+// toleransi sList[0]. Pasangan terpilih dipindah ke posisi depan dengan
+// menggeser record sebelumnya sehingga sisa daftar tetap terurut.
+func chooseByDescription(sList []model.NormalizedRecord, bList []bankRec, opts Options) {
+	sRun, bRun := 1, 1
+	for sRun < len(sList) && abs64(sList[sRun].Amount-bList[0].Amount) <= opts.toleranceFor(bList[0].BankName) {
+		sRun++
+	}
+	for bRun < len(bList) && bList[bRun].Amount-sList[0].Amount <= opts.maxTolerance() {
+		bRun++
+	}
+	if sRun == 1 && bRun == 1 {
+		return
+	}
+	bestS, bestB, best := 0, 0, -1.0
+	for p := 0; p < sRun; p++ {
+		sText := sList[p].ID + " " + sList[p].Description
+		for q := 0; q < bRun; q++ {
+			if abs64(sList[p].Amount-bList[q].Amount) > opts.toleranceFor(bList[q].BankName) {
+				continue
+			}
+			score := textSimilarity(sText, bList[q].Reference+" "+bList[q].Description)
+			if score > best {
+				bestS, bestB, best = p, q, score
+			}
 		}
 	}
+	s, b := sList[bestS], bList[bestB]
+	copy(sList[1:bestS+1], sList[:bestS])
+	copy(bList[1:bestB+1], bList[:bestB])
+	sList[0], bList[0] = s, b
+}
 
-	return matched, unmatchedSys, unmatchedBank
+// findMisrouted mencari, untuk setiap record sistem ber-Account yang
+// unmatched, statement unmatched dari bank/rekening lain dengan selisih
+// terkecil dalam toleransi. Setiap statement dipakai paling banyak sekali.
+func findMisrouted(d time.Time, sys []model.NormalizedRecord, bank []bankRec, opts Options) []model.MisroutedMatch {
+	var out []model.MisroutedMatch
+	used := make([]bool, len(bank))
+	for _, s := range sys {
+		best := -1
+		var bestDiff int64
+		for i, b := range bank {
+			if used[i] || b.routesTo(s.Account) {
+				continue
+			}
+			diff := abs64(s.Amount - b.Amount)
+			if diff <= opts.toleranceFor(b.BankName) && (best < 0 || diff < bestDiff) {
+				best, bestDiff = i, diff
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		used[best] = true
+		b := bank[best]
+		out = append(out, model.MisroutedMatch{
+			SystemID:        s.ID,
+			ExpectedAccount: s.Account,
+			BankID:          b.ID,
+			BankName:        b.BankName,
+			BankAccount:     b.Account,
+			Date:            d.Format("2006-01-02"),
+			SystemAmount:    s.Amount,
+			BankAmount:      b.Amount,
+		})
+	}
+	return out
 }
@@ -1,6 +1,7 @@
 package reconcile
 
 import (
+    "fmt"
     "testing"
     "time"
 
@@ -56,8 +57,560 @@ func mustRFC3339(s string) time.Time {
     if err != nil { panic(err) }
     return t
 }
+
 func mustDate(s string) time.Time {
     t, err := time.Parse("2006-01-02", s)
     if err != nil { panic(err) }
     return t
-}
\ No newline at end of file
+}
+
+func TestReconcileWithOptions_Tolerance(t *testing.T) {
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T10:00:00Z")},
+        {TrxID: "TRX-2", Amount: 200000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T18:00:00Z")},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {{UniqueIdentifier: "BA-1", Amount: 98000, Date: mustDate("2025-06-01"), BankName: "bankA"}},
+        "bankB": {{UniqueIdentifier: "BB-1", Amount: 193000, Date: mustDate("2025-06-02"), BankName: "bankB"}},
+    }
+    start, end := mustDate("2025-06-01"), mustDate("2025-06-02")
+
+    opts := DefaultOptions()
+    opts.Tolerance = 1000
+    opts.BankTolerances = map[string]int64{"bankB": 10000}
+    // 18:00 UTC = 01:00 WIB keesokan harinya.
+    opts.Location = time.FixedZone("WIB", 7*3600)
+
+    res, err := ReconcileWithOptions(sys, banks, start, end, opts)
+    if err != nil { t.Fatalf("error: %v", err) }
+    if res.Summary.TotalMatched != 1 || res.Details.Matched[0].BankID != "BB-1" {
+        t.Fatalf("expected only BB-1 matched, got %+v", res.Details.Matched)
+    }
+    if res.Summary.TotalUnmatched != 2 {
+        t.Fatalf("expected total unmatched 2, got %d", res.Summary.TotalUnmatched)
+    }
+
+    opts.Strategy = "unknown"
+    if _, err := ReconcileWithOptions(sys, banks, start, end, opts); err == nil {
+        t.Fatalf("expected error for unknown strategy")
+    }
+}
+
+func TestReconcile_BalanceBreaks(t *testing.T) {
+    day := func(d int) time.Time { return time.Date(2025, 6, d, 0, 0, 0, 0, time.UTC) }
+    opts := DefaultOptions()
+    opts.Balances = []loader.StatementBalance{
+        {BankName: "bankB", Source: "b.sta", Date: day(1), Opening: 100, Total: 50, Closing: 150},
+        {BankName: "bankB", Source: "b.sta", Date: day(2), Opening: 150, Total: 50, Closing: 190},
+        {BankName: "bankA", Source: "a.csv", Date: day(3), Opening: 0, Total: -10, Closing: 0},
+        {BankName: "bankA", Source: "a.csv", Date: day(9), Opening: 0, Total: 10, Closing: 0}, // di luar rentang
+    }
+    res, err := ReconcileWithOptions(nil, nil, day(1), day(5), opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    breaks := res.Details.BalanceBreaks
+    if res.Summary.TotalBalanceBreaks != 2 || len(breaks) != 2 {
+        t.Fatalf("expected 2 balance breaks, got %+v", breaks)
+    }
+    if breaks[0].BankName != "bankA" || breaks[0].Date != "2025-06-03" || breaks[0].Difference != -10 {
+        t.Fatalf("unexpected first break: %+v", breaks[0])
+    }
+    if breaks[1].BankName != "bankB" || breaks[1].Date != "2025-06-02" || breaks[1].Difference != 10 {
+        t.Fatalf("unexpected second break: %+v", breaks[1])
+    }
+}
+
+func TestReconcile_BalanceReconciliation(t *testing.T) {
+    sys := []model.SystemTransaction{
+        {TrxID: "S1", Amount: 250000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T10:00:00Z")},
+        {TrxID: "S2", Amount: 125000, Type: "DEBIT", TransactionTime: mustRFC3339("2025-06-01T11:00:00Z")},
+        {TrxID: "S3", Amount: 10000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T12:00:00Z")},
+    }
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "A1", Amount: 249000, Date: day, BankName: "bankA"},
+            {UniqueIdentifier: "A2", Amount: -125000, Date: day, BankName: "bankA"},
+            {UniqueIdentifier: "A3", Amount: 50000, Date: day, BankName: "bankA"},
+        },
+        "bankB": {
+            {UniqueIdentifier: "B1", Amount: 30000, Date: day, BankName: "bankB"},
+        },
+    }
+    opts := DefaultOptions()
+    opts.BalanceReconciliation = true
+    // Saldo bankB hanya bergerak 20000 padahal barisnya 30000.
+    opts.Balances = []loader.StatementBalance{{BankName: "bankB", Date: day, Opening: 0, Closing: 20000, Total: 30000}}
+
+    res, err := ReconcileWithOptions(sys, banks, day, day, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    br := res.BalanceReconciliation
+    if br == nil || len(br.ByBank) != 2 || len(br.Unattributed) != 1 || len(br.Totals) != 1 {
+        t.Fatalf("unexpected balance reconciliation: %+v", br)
+    }
+    a := br.ByBank[0]
+    if a.BankName != "bankA" || a.SystemTotal != 125000 || a.BankTotal != 174000 || a.Difference != -49000 ||
+        a.MatchedDifference != 1000 || a.UnmatchedBank != 50000 || a.Residue != 0 || !a.Explained {
+        t.Fatalf("unexpected bankA line: %+v", a)
+    }
+    b := br.ByBank[1]
+    if b.BankName != "bankB" || b.BankTotal != 20000 || b.UnmatchedBank != 30000 || b.Residue != 10000 || b.Explained {
+        t.Fatalf("unexpected bankB line: %+v", b)
+    }
+    // S3 tidak matched dan tidak memiliki rekening tujuan.
+    if u := br.Unattributed[0]; u.SystemTotal != 10000 || u.UnmatchedSystem != 10000 || !u.Explained {
+        t.Fatalf("unexpected unattributed line: %+v", u)
+    }
+    tot := br.Totals[0]
+    if tot.Date != "2025-06-01" || tot.SystemTotal != 135000 || tot.BankTotal != 194000 || tot.UnmatchedSystem != 10000 ||
+        tot.UnmatchedBank != 80000 || tot.Residue != 10000 {
+        t.Fatalf("unexpected total line: %+v", tot)
+    }
+    if br.Unexplained != 2 {
+        t.Fatalf("expected 2 unexplained lines, got %d", br.Unexplained)
+    }
+
+    res, _ = ReconcileWithOptions(sys, banks, day, day, DefaultOptions())
+    if res.BalanceReconciliation != nil {
+        t.Fatalf("balance reconciliation must be opt-in")
+    }
+}
+
+func TestReconcile_AccountRouting(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "S1", Amount: 100000, Type: "CREDIT", TransactionTime: at, Account: "bankA"},
+        {TrxID: "S2", Amount: 200000, Type: "CREDIT", TransactionTime: at, Account: "bankB"},
+        {TrxID: "S3", Amount: 50000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "S4", Amount: 75000, Type: "CREDIT", TransactionTime: at, Account: "ID12BANK0001"},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "A1", Amount: 200000, Date: day, BankName: "bankA"},
+            {UniqueIdentifier: "A2", Amount: 75000, Date: day, BankName: "bankA", Account: "ID12BANK0001"},
+        },
+        "bankB": {
+            {UniqueIdentifier: "B1", Amount: 100000, Date: day, BankName: "bankB"},
+            {UniqueIdentifier: "B2", Amount: 50000, Date: day, BankName: "bankB"},
+        },
+    }
+    res, err := ReconcileWithOptions(sys, banks, day, day, DefaultOptions())
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    pairs := map[string]string{}
+    for _, m := range res.Details.Matched {
+        pairs[m.SystemID] = m.BankID
+    }
+    if len(pairs) != 2 || pairs["S3"] != "B2" || pairs["S4"] != "A2" {
+        t.Fatalf("unexpected matches: %+v", res.Details.Matched)
+    }
+    if len(res.Details.UnmatchedSystem) != 2 || res.Summary.TotalUnmatched != 4 {
+        t.Fatalf("expected S1 and S2 unmatched, got %+v", res.Details.UnmatchedSystem)
+    }
+    mis := res.Details.Misrouted
+    if res.Summary.TotalMisrouted != 2 || len(mis) != 2 {
+        t.Fatalf("expected 2 misrouted, got %+v", mis)
+    }
+    got := map[string]string{}
+    for _, m := range mis {
+        got[m.SystemID] = m.ExpectedAccount + "->" + m.BankName + "/" + m.BankID
+    }
+    if got["S1"] != "bankA->bankB/B1" || got["S2"] != "bankB->bankA/A1" {
+        t.Fatalf("unexpected misrouted: %+v", mis)
+    }
+}
+
+func TestReconcile_BalanceReconciliationRouting(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "S1", Amount: 100000, Type: "CREDIT", TransactionTime: at, Account: "bankA"},
+        {TrxID: "S2", Amount: 60000, Type: "DEBIT", TransactionTime: at, Account: "ID12BANK0002"},
+        {TrxID: "S3", Amount: 40000, Type: "CREDIT", TransactionTime: at, Account: "bankZ"},
+        {TrxID: "S4", Amount: 20000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "S5", Amount: 500000, Type: "CREDIT", TransactionTime: at, Account: "bankA"},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {{UniqueIdentifier: "A1", Amount: 500000, Date: day, BankName: "bankA"}},
+        // Rekening ID12BANK0002 hanya dikenal lewat statement di luar rentang.
+        "bankB": {{UniqueIdentifier: "B1", Amount: 1000, Date: day.AddDate(0, 0, -1), BankName: "bankB", Account: "ID12BANK0002"}},
+    }
+    opts := DefaultOptions()
+    opts.BalanceReconciliation = true
+    res, err := ReconcileWithOptions(sys, banks, day, day, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    br := res.BalanceReconciliation
+    if len(br.ByBank) != 2 || len(br.Unattributed) != 1 {
+        t.Fatalf("unexpected balance reconciliation: %+v", br)
+    }
+    // S1 tidak matched tetapi tetap muncul pada baris bankA.
+    a := br.ByBank[0]
+    if a.BankName != "bankA" || a.SystemTotal != 600000 || a.BankTotal != 500000 || a.UnmatchedSystem != 100000 || !a.Explained {
+        t.Fatalf("unexpected bankA line: %+v", a)
+    }
+    b := br.ByBank[1]
+    if b.BankName != "bankB" || b.SystemTotal != -60000 || b.UnmatchedSystem != -60000 || b.Difference != -60000 || !b.Explained {
+        t.Fatalf("unexpected bankB line: %+v", b)
+    }
+    // bankZ tidak dikenal dan S4 tanpa rekening.
+    if u := br.Unattributed[0]; u.SystemTotal != 60000 || u.UnmatchedSystem != 60000 {
+        t.Fatalf("unexpected unattributed line: %+v", u)
+    }
+    if tot := br.Totals[0]; tot.SystemTotal != a.SystemTotal+b.SystemTotal+br.Unattributed[0].SystemTotal {
+        t.Fatalf("totals do not add up: %+v", br)
+    }
+}
+
+func TestReconcile_DescriptionTieBreak(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 250000, Type: "CREDIT", TransactionTime: at, Description: "Angsuran L-0042 Siti Aminah"},
+        {TrxID: "TRX-2", Amount: 250000, Type: "CREDIT", TransactionTime: at, Description: "Angsuran L-0077 Budi Santoso"},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: 250000, Date: day, Description: "TRF BUDI SANTOSO L0077"},
+            {UniqueIdentifier: "BA-2", Amount: 250000, Date: day, Description: "TRF SITI AMINAH L-0042"},
+        },
+    }
+    opts := DefaultOptions()
+    opts.DescriptionTieBreak = true
+    res, err := ReconcileWithOptions(sys, banks, day, day, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    pairs := map[string]string{}
+    for _, m := range res.Details.Matched {
+        pairs[m.SystemID] = m.BankID
+    }
+    if len(pairs) != 2 || pairs["TRX-1"] != "BA-2" || pairs["TRX-2"] != "BA-1" {
+        t.Fatalf("unexpected pairs: %+v", res.Details.Matched)
+    }
+}
+
+func TestReconcile_DescriptionTieBreakWithinTolerance(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "CREDIT", TransactionTime: at, Description: "Angsuran L-0042 Siti Aminah"},
+        {TrxID: "TRX-2", Amount: 100000, Type: "CREDIT", TransactionTime: at, Description: "Angsuran L-0077 Budi Santoso"},
+    }
+    // Amount berbeda 1 tetapi sama-sama dalam toleransi: tanpa tie-breaker
+    // pasangan mengikuti urutan amount.
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: 100000, Date: day, Description: "TRF BUDI SANTOSO L0077"},
+            {UniqueIdentifier: "BA-2", Amount: 100001, Date: day, Description: "TRF SITI AMINAH L-0042"},
+        },
+    }
+    opts := DefaultOptions()
+    opts.Tolerance = 1
+    for _, tc := range []struct {
+        tieBreak bool
+        want     map[string]string
+    }{
+        {false, map[string]string{"TRX-1": "BA-1", "TRX-2": "BA-2"}},
+        {true, map[string]string{"TRX-1": "BA-2", "TRX-2": "BA-1"}},
+    } {
+        opts.DescriptionTieBreak = tc.tieBreak
+        res, err := ReconcileWithOptions(sys, banks, day, day, opts)
+        if err != nil {
+            t.Fatalf("ReconcileWithOptions error: %v", err)
+        }
+        pairs := map[string]string{}
+        for _, m := range res.Details.Matched {
+            pairs[m.SystemID] = m.BankID
+            if m.Discrepancy != abs64(m.SystemAmount-m.BankAmount) {
+                t.Fatalf("discrepancy does not match chosen pair: %+v", m)
+            }
+        }
+        if len(pairs) != 2 || pairs["TRX-1"] != tc.want["TRX-1"] || pairs["TRX-2"] != tc.want["TRX-2"] {
+            t.Fatalf("tiebreak=%v: unexpected pairs: %+v", tc.tieBreak, res.Details.Matched)
+        }
+    }
+}
+
+func TestReconcile_FeeClassification(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-2", Amount: 200000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-3", Amount: 300000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-4", Amount: 400000, Type: "DEBIT", TransactionTime: at},
+        {TrxID: "TRX-5", Amount: 500000, Type: "CREDIT", TransactionTime: at},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: 100000, Date: day},  // exact
+            {UniqueIdentifier: "BA-2", Amount: 197500, Date: day},  // fee tetap 2500
+            {UniqueIdentifier: "BA-3", Amount: 297900, Date: day},  // MDR 0.7% = 2100
+            {UniqueIdentifier: "BA-4", Amount: -402500, Date: day}, // fee tetap pada debit
+            {UniqueIdentifier: "BA-5", Amount: 501000, Date: day},  // bank lebih besar: tidak dijelaskan
+        },
+    }
+    opts := DefaultOptions()
+    opts.FeeRules = []FeeRule{{Bank: "bankA", Fixed: []int64{2500}}, {MDRPercent: 0.7}, {Bank: "bankB", Fixed: []int64{1000}}}
+    res, err := ReconcileWithOptions(sys, banks, day, day, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    want := map[string]struct {
+        category string
+        fee      int64
+    }{
+        "TRX-1": {model.MatchExact, 0},
+        "TRX-2": {model.MatchWithFee, 2500},
+        "TRX-3": {model.MatchWithFee, 2100},
+        "TRX-4": {model.MatchWithFee, 2500},
+        "TRX-5": {model.MatchDiscrepancy, 0},
+    }
+    if len(res.Details.Matched) != len(want) {
+        t.Fatalf("expected %d matched, got %+v", len(want), res.Details.Matched)
+    }
+    for _, m := range res.Details.Matched {
+        if w := want[m.SystemID]; m.Category != w.category || m.Fee != w.fee {
+            t.Fatalf("%s: expected %s fee %d, got %+v", m.SystemID, w.category, w.fee, m)
+        }
+    }
+    if res.Summary.TotalMatchedWithFee != 3 || res.Summary.TotalFees != 7100 || res.Summary.TotalUnexplainedDiscrepancies != 1 {
+        t.Fatalf("unexpected summary: %+v", res.Summary)
+    }
+    if len(res.Details.UnexplainedDiscrepancies) != 1 || res.Details.UnexplainedDiscrepancies[0].SystemID != "TRX-5" {
+        t.Fatalf("unexpected unexplained discrepancies: %+v", res.Details.UnexplainedDiscrepancies)
+    }
+
+    // Tanpa aturan fee, setiap selisih tidak dijelaskan.
+    res, err = ReconcileWithOptions(sys, banks, day, day, DefaultOptions())
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    if res.Summary.TotalMatchedWithFee != 0 || res.Summary.TotalUnexplainedDiscrepancies != 4 {
+        t.Fatalf("unexpected summary without fee rules: %+v", res.Summary)
+    }
+
+    for _, r := range []FeeRule{{}, {Fixed: []int64{0}}, {MDRPercent: -1}, {MDRPercent: 100}} {
+        opts := DefaultOptions()
+        opts.FeeRules = []FeeRule{r}
+        if err := opts.Validate(); err == nil {
+            t.Fatalf("expected validation error for %+v", r)
+        }
+    }
+}
+
+func TestReconcile_Reversals(t *testing.T) {
+    start, end := mustDate("2025-06-01"), mustDate("2025-06-03")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T09:00:00Z")},
+        {TrxID: "TRX-1R", Amount: 100000, Type: "DEBIT", TransactionTime: mustRFC3339("2025-06-02T09:00:00Z"), Description: "Batal TRX-1"},
+        {TrxID: "TRX-2", Amount: 50000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T10:00:00Z")},
+        {TrxID: "TRX-3", Amount: 30000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T11:00:00Z")},
+        {TrxID: "TRX-4", Amount: 30000, Type: "DEBIT", TransactionTime: mustRFC3339("2025-06-01T12:00:00Z")},
+        // Pencairan dan angsuran bernominal sama, masing-masing punya record bank.
+        {TrxID: "TRX-5", Amount: 20000, Type: "CREDIT", TransactionTime: mustRFC3339("2025-06-01T13:00:00Z"), Description: "Angsuran"},
+        {TrxID: "TRX-6", Amount: 20000, Type: "DEBIT", TransactionTime: mustRFC3339("2025-06-01T14:00:00Z"), Description: "Pencairan"},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: -75000, Date: start, Reference: "RV-9"},
+            {UniqueIdentifier: "BA-1R", Amount: 75000, Date: end, Reference: "RV-9"},
+            {UniqueIdentifier: "BA-2", Amount: 50000, Date: start},
+            {UniqueIdentifier: "BA-5", Amount: 20000, Date: start},
+            {UniqueIdentifier: "BA-6", Amount: -20000, Date: start},
+        },
+    }
+
+    // Default: hanya pasangan yang saling merujuk; TRX-3/TRX-4 tetap di pool matching.
+    opts := DefaultOptions()
+    opts.Reversals = true
+    opts.ReversalWindow = 2
+    opts.BalanceReconciliation = true
+    res, err := ReconcileWithOptions(sys, banks, start, end, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    want := []model.Reversal{
+        {Source: model.ReversalSourceSystem, OriginalID: "TRX-1", ReversalID: "TRX-1R", OriginalDate: "2025-06-01", ReversalDate: "2025-06-02", Amount: 100000, Linked: true},
+        {Source: "bankA", OriginalID: "BA-1", ReversalID: "BA-1R", OriginalDate: "2025-06-01", ReversalDate: "2025-06-03", Amount: -75000, Linked: true},
+    }
+    if len(res.Details.Reversals) != len(want) {
+        t.Fatalf("expected %d reversals, got %+v", len(want), res.Details.Reversals)
+    }
+    for i, w := range want {
+        if res.Details.Reversals[i] != w {
+            t.Fatalf("reversal %d: expected %+v, got %+v", i, w, res.Details.Reversals[i])
+        }
+    }
+    if len(res.Details.Matched) != 3 || len(res.Details.UnmatchedSystem) != 2 ||
+        res.Summary.TotalReversals != 2 || res.Summary.TotalProcessed != 12 {
+        t.Fatalf("unexpected summary: %+v", res.Summary)
+    }
+
+    // allow_unlinked menerima TRX-3/TRX-4 yang tidak punya record bank, tetapi
+    // TRX-5/TRX-6 tetap di-match dengan BA-5/BA-6.
+    opts.ReversalAllowUnlinked = true
+    res, err = ReconcileWithOptions(sys, banks, start, end, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    unlinked := model.Reversal{Source: model.ReversalSourceSystem, OriginalID: "TRX-3", ReversalID: "TRX-4", OriginalDate: "2025-06-01", ReversalDate: "2025-06-01", Amount: 30000}
+    if res.Summary.TotalReversals != 3 || res.Details.Reversals[1] != unlinked {
+        t.Fatalf("unexpected reversals with allow unlinked: %+v", res.Details.Reversals)
+    }
+    matched := map[string]string{}
+    for _, m := range res.Details.Matched {
+        matched[m.SystemID] = m.BankID
+    }
+    if len(matched) != 3 || matched["TRX-2"] != "BA-2" || matched["TRX-5"] != "BA-5" || matched["TRX-6"] != "BA-6" ||
+        res.Summary.TotalUnmatched != 0 || res.Summary.TotalProcessed != 12 {
+        t.Fatalf("unexpected matches with allow unlinked: %+v", res.Details.Matched)
+    }
+    if res.BalanceReconciliation.Unexplained != 0 {
+        t.Fatalf("expected reversals to be explained in balance reconciliation: %+v", res.BalanceReconciliation)
+    }
+
+    // Jendela 0 hari hanya menerima pembatalan pada hari yang sama.
+    opts.ReversalWindow = 0
+    res, err = ReconcileWithOptions(sys, banks, start, end, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    if res.Summary.TotalReversals != 1 || res.Details.Reversals[0].OriginalID != "TRX-3" {
+        t.Fatalf("unexpected reversals with same-day window: %+v", res.Details.Reversals)
+    }
+}
+
+func TestReconcile_SignAgnostic(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-2", Amount: 200000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-3", Amount: 300000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-4", Amount: 400000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-5", Amount: 50000, Type: "DEBIT", TransactionTime: at},
+        {TrxID: "TRX-6", Amount: 600000, Type: "CREDIT", TransactionTime: at},
+        {TrxID: "TRX-7", Amount: 70000, Type: "CREDIT", TransactionTime: at},
+    }
+    // bankA diekspor dengan tanda terbalik; bankB normal.
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: -99000, Date: day},
+            {UniqueIdentifier: "BA-2", Amount: -200000, Date: day},
+            {UniqueIdentifier: "BA-3", Amount: -300000, Date: day},
+            {UniqueIdentifier: "BA-4", Amount: -400000, Date: day},
+            {UniqueIdentifier: "BA-5", Amount: 50000, Date: day},
+            {UniqueIdentifier: "BA-6", Amount: -600000, Date: day},
+        },
+        "bankB": {
+            {UniqueIdentifier: "BB-1", Amount: 70000, Date: day},
+        },
+    }
+
+    res, err := Reconcile(sys, banks, day, day)
+    if err != nil {
+        t.Fatalf("Reconcile error: %v", err)
+    }
+    if len(res.Details.SignInversions) != 1 {
+        t.Fatalf("expected one sign inversion warning, got %+v", res.Details.SignInversions)
+    }
+    if inv := res.Details.SignInversions[0]; inv.BankName != "bankA" || inv.Compared != 5 || inv.Inverted != 5 || inv.Ratio != 1 {
+        t.Fatalf("unexpected sign inversion: %+v", inv)
+    }
+    if res.Summary.TotalMatched != 1 || res.Summary.TotalSignMismatches != 0 {
+        t.Fatalf("expected only bankB matched without sign-agnostic mode: %+v", res.Summary)
+    }
+
+    opts := DefaultOptions()
+    opts.SignAgnostic = true
+    opts.FeeRules = []FeeRule{{Fixed: []int64{1000}}}
+    res, err = ReconcileWithOptions(sys, banks, day, day, opts)
+    if err != nil {
+        t.Fatalf("ReconcileWithOptions error: %v", err)
+    }
+    if res.Summary.TotalMatched != 7 || res.Summary.TotalUnmatched != 0 || res.Summary.TotalSignMismatches != 6 {
+        t.Fatalf("unexpected summary in sign-agnostic mode: %+v", res.Summary)
+    }
+    for _, m := range res.Details.Matched {
+        if m.SignMismatch != (m.BankName == "bankA") {
+            t.Fatalf("unexpected sign mismatch flag: %+v", m)
+        }
+        if m.SystemID == "TRX-1" && (m.BankAmount != -99000 || m.Discrepancy != 1000 || m.Category != model.MatchWithFee || m.Fee != 1000) {
+            t.Fatalf("unexpected flipped fee pair: %+v", m)
+        }
+    }
+}
+
+func TestReconcile_SignInversionPerFile(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    var sys []model.SystemTransaction
+    var stmts []loader.BankStatement
+    // a.csv diekspor dengan tanda terbalik, b.csv normal; keduanya bankA.
+    for i := 1; i <= 11; i++ {
+        amount := int64(i) * 10000
+        sys = append(sys, model.SystemTransaction{TrxID: fmt.Sprintf("TRX-%d", i), Amount: amount, Type: "CREDIT", TransactionTime: at})
+        st := loader.BankStatement{UniqueIdentifier: fmt.Sprintf("BA-%d", i), Amount: amount, Date: day, Source: "b.csv"}
+        if i <= 5 {
+            st.Amount, st.Source = -amount, "a.csv"
+        }
+        stmts = append(stmts, st)
+    }
+
+    res, err := Reconcile(sys, map[string][]loader.BankStatement{"bankA": stmts}, day, day)
+    if err != nil {
+        t.Fatalf("Reconcile error: %v", err)
+    }
+    // Digabung per bank hanya 5 dari 11 terbalik; per file a.csv 5 dari 5.
+    want := []model.SignInversion{{BankName: "bankA", Source: "a.csv", Compared: 5, Inverted: 5, Ratio: 1}}
+    if len(res.Details.SignInversions) != 1 || res.Details.SignInversions[0] != want[0] {
+        t.Fatalf("unexpected sign inversions: %+v", res.Details.SignInversions)
+    }
+}
+
+func TestReconcile_UnknownTypes(t *testing.T) {
+    day := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+    at := mustRFC3339("2025-06-01T10:00:00Z")
+    sys := []model.SystemTransaction{
+        {TrxID: "TRX-1", Amount: 100000, Type: "debit", TransactionTime: at},
+        {TrxID: "TRX-2", Amount: 50000, Type: "DEBT", TransactionTime: at},
+        // Alias bawaan loader juga dikenali engine tanpa melewati loader.
+        {TrxID: "TRX-3", Amount: 30000, Type: " cr ", TransactionTime: at},
+        {TrxID: "TRX-4", Amount: 20000, Type: "Dr", TransactionTime: at},
+    }
+    banks := map[string][]loader.BankStatement{
+        "bankA": {
+            {UniqueIdentifier: "BA-1", Amount: -100000, Date: day},
+            {UniqueIdentifier: "BA-2", Amount: 50000, Date: day},
+            {UniqueIdentifier: "BA-3", Amount: 30000, Date: day},
+            {UniqueIdentifier: "BA-4", Amount: -20000, Date: day},
+        },
+    }
+    res, err := Reconcile(sys, banks, day, day)
+    if err != nil {
+        t.Fatalf("Reconcile error: %v", err)
+    }
+    // TRX-2 tidak boleh dianggap credit dan dipasangkan dengan BA-2.
+    matched := map[string]string{}
+    for _, m := range res.Details.Matched {
+        matched[m.SystemID] = m.BankID
+    }
+    if len(matched) != 3 || matched["TRX-1"] != "BA-1" || matched["TRX-3"] != "BA-3" || matched["TRX-4"] != "BA-4" {
+        t.Fatalf("unexpected matches: %+v", res.Details.Matched)
+    }
+    want := model.UnknownTypeTransaction{SystemID: "TRX-2", Type: "DEBT", Amount: 50000, Date: "2025-06-01"}
+    if len(res.Details.UnknownTypes) != 1 || res.Details.UnknownTypes[0] != want {
+        t.Fatalf("unexpected unknown types: %+v", res.Details.UnknownTypes)
+    }
+    if res.Summary.TotalUnknownTypes != 1 || res.Summary.TotalProcessed != 8 || res.Summary.TotalUnmatched != 1 {
+        t.Fatalf("unexpected summary: %+v", res.Summary)
+    }
+}
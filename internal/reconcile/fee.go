@@ -0,0 +1,82 @@
+package reconcile
+
+import (
+	"fmt"
+	"math"
+
+	"amartha/internal/model"
+)
+
+// FeeRule mendeskripsikan biaya bank yang diketahui. Selisih pasangan matched
+// (amount sistem - amount bank) yang dijelaskan aturan ini dilaporkan sebagai
+// matched dengan fee; selisih lain ditandai sebagai discrepancy.
+type FeeRule struct {
+	Bank       string  `json:"bank"`        // nama bank; kosong = semua bank
+	Fixed      []int64 `json:"fixed"`       // nominal biaya tetap, mis. [2500, 6500]
+	MDRPercent float64 `json:"mdr_percent"` // persentase MDR dari amount sistem, mis. 0.7
+}
+
+func (r FeeRule) validate() error {
+	if len(r.Fixed) == 0 && r.MDRPercent == 0 {
+		return fmt.Errorf("fixed or mdr_percent is required")
+	}
+	for _, f := range r.Fixed {
+		if f <= 0 {
+			return fmt.Errorf("fixed fee must be positive: %d", f)
+		}
+	}
+	if r.MDRPercent < 0 || r.MDRPercent >= 100 {
+		return fmt.Errorf("mdr_percent must be in [0, 100): %v", r.MDRPercent)
+	}
+	return nil
+}
+
+// explains melaporkan apakah fee sama dengan salah satu biaya tetap, MDR
+// dari amount (dibulatkan ke bawah atau ke atas), atau MDR + biaya tetap.
+func (r FeeRule) explains(amount, fee int64) bool {
+	var mdr []int64
+	if r.MDRPercent > 0 {
+		v := float64(abs64(amount)) * r.MDRPercent / 100
+		lo, hi := int64(math.Floor(v+1e-9)), int64(math.Ceil(v-1e-9))
+		if fee == lo || fee == hi {
+			return true
+		}
+		mdr = []int64{lo, hi}
+	} else {
+		mdr = []int64{0}
+	}
+	for _, f := range r.Fixed {
+		for _, m := range mdr {
+			if fee == m+f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// classifyMatch mengisi Category dan Fee pasangan matched: tanpa selisih
+// berarti exact, selisih yang dijelaskan FeeRules bank tersebut berarti
+// matched dengan fee, selain itu discrepancy yang perlu ditelusuri.
+func (o Options) classifyMatch(p *model.MatchedPair) {
+	if p.Discrepancy == 0 {
+		p.Category = model.MatchExact
+		return
+	}
+	// Fee selalu mengurangi dana masuk atau menambah dana keluar.
+	bankAmount := p.BankAmount
+	if p.SignMismatch {
+		bankAmount = -bankAmount
+	}
+	fee := p.SystemAmount - bankAmount
+	if fee > 0 {
+		for _, r := range o.FeeRules {
+			if (r.Bank == "" || r.Bank == p.BankName) && r.explains(p.SystemAmount, fee) {
+				p.Category = model.MatchWithFee
+				p.Fee = fee
+				return
+			}
+		}
+	}
+	p.Category = model.MatchDiscrepancy
+}
@@ -0,0 +1,161 @@
+package reconcile
+
+import (
+	"sort"
+	"time"
+
+	"amartha/internal/loader"
+	"amartha/internal/model"
+)
+
+type bankDateKey struct {
+	bank, date string
+}
+
+// reconcileBalances menyusun rekonsiliasi saldo per bank & tanggal dan total
+// per tanggal. Mutasi bank memakai saldo akhir - saldo awal statement bila
+// tersedia untuk bank & tanggal tersebut, selain itu jumlah baris statement;
+// selisih keduanya muncul sebagai residue. Transaksi sistem diatribusikan ke
+// bank lewat pasangan matched, atau lewat rekening tujuannya (Account, lihat
+// accountBanks) bila tidak matched; sisanya masuk ke baris Unattributed per
+// tanggal sehingga ByBank + Unattributed = Totals.
+func reconcileBalances(
+	sys []model.NormalizedRecord,
+	bank []bankRec,
+	matched []model.MatchedPair,
+	unmatchedSys []model.NormalizedRecord,
+	unmatchedBank map[string][]model.NormalizedRecord,
+	routes map[string]string,
+	bals []loader.StatementBalance,
+	start, end time.Time,
+) *model.BalanceReconciliation {
+	lines := map[bankDateKey]*model.BalanceLine{}
+	line := func(bank, date string) *model.BalanceLine {
+		k := bankDateKey{bank, date}
+		if l, ok := lines[k]; ok {
+			return l
+		}
+		l := &model.BalanceLine{BankName: bank, Date: date}
+		lines[k] = l
+		return l
+	}
+
+	rowTotals := map[bankDateKey]int64{}
+	for _, b := range bank {
+		k := bankDateKey{b.BankName, b.Date.Format("2006-01-02")}
+		rowTotals[k] += b.Amount
+		line(k.bank, k.date)
+	}
+	movements := map[bankDateKey]int64{}
+	for _, b := range bals {
+		d := time.Date(b.Date.Year(), b.Date.Month(), b.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if d.Before(start) || d.After(end) {
+			continue
+		}
+		k := bankDateKey{b.BankName, d.Format("2006-01-02")}
+		movements[k] += b.Closing - b.Opening
+		line(k.bank, k.date)
+	}
+	for k, l := range lines {
+		if m, ok := movements[k]; ok {
+			l.BankTotal = m
+		} else {
+			l.BankTotal = rowTotals[k]
+		}
+	}
+	for _, m := range matched {
+		l := line(m.BankName, m.Date)
+		l.SystemTotal += m.SystemAmount
+		l.MatchedDifference += m.SystemAmount - m.BankAmount
+	}
+	for bankName, recs := range unmatchedBank {
+		for _, r := range recs {
+			line(bankName, r.Date.Format("2006-01-02")).UnmatchedBank += r.Amount
+		}
+	}
+
+	totals := map[string]*model.BalanceLine{}
+	total := func(date string) *model.BalanceLine {
+		if t, ok := totals[date]; ok {
+			return t
+		}
+		t := &model.BalanceLine{Date: date}
+		totals[date] = t
+		return t
+	}
+	for _, s := range sys {
+		total(s.Date.Format("2006-01-02")).SystemTotal += s.Amount
+	}
+	unattributed := map[string]*model.BalanceLine{}
+	for _, s := range unmatchedSys {
+		date := s.Date.Format("2006-01-02")
+		total(date).UnmatchedSystem += s.Amount
+		var l *model.BalanceLine
+		if bankName, ok := routes[s.Account]; ok && s.Account != "" {
+			l = line(bankName, date)
+		} else if l = unattributed[date]; l == nil {
+			l = &model.BalanceLine{Date: date}
+			unattributed[date] = l
+		}
+		l.SystemTotal += s.Amount
+		l.UnmatchedSystem += s.Amount
+	}
+	for _, l := range lines {
+		t := total(l.Date)
+		t.BankTotal += l.BankTotal
+		t.MatchedDifference += l.MatchedDifference
+		t.UnmatchedBank += l.UnmatchedBank
+	}
+
+	out := &model.BalanceReconciliation{ByBank: []model.BalanceLine{}, Unattributed: []model.BalanceLine{}, Totals: []model.BalanceLine{}}
+	for _, l := range lines {
+		out.ByBank = append(out.ByBank, settleLine(*l))
+	}
+	for _, u := range unattributed {
+		out.Unattributed = append(out.Unattributed, settleLine(*u))
+	}
+	for _, t := range totals {
+		out.Totals = append(out.Totals, settleLine(*t))
+	}
+	sort.Slice(out.ByBank, func(i, j int) bool {
+		a, b := out.ByBank[i], out.ByBank[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		return a.BankName < b.BankName
+	})
+	sort.Slice(out.Unattributed, func(i, j int) bool { return out.Unattributed[i].Date < out.Unattributed[j].Date })
+	sort.Slice(out.Totals, func(i, j int) bool { return out.Totals[i].Date < out.Totals[j].Date })
+	for _, l := range append(out.ByBank, out.Totals...) {
+		if !l.Explained {
+			out.Unexplained++
+		}
+	}
+	return out
+}
+
+// accountBanks memetakan rekening tujuan transaksi sistem ke nama bank: nama
+// bank itu sendiri, atau rekening (Account) pada statement bank tersebut.
+// Rekening yang muncul di beberapa bank dipetakan ke bank pertama menurut nama.
+func accountBanks(banks map[string][]loader.BankStatement, names []string) map[string]string {
+	routes := map[string]string{}
+	for _, name := range names {
+		routes[name] = name
+	}
+	for _, name := range names {
+		for _, b := range banks[name] {
+			if _, ok := routes[b.Account]; !ok && b.Account != "" {
+				routes[b.Account] = name
+			}
+		}
+	}
+	return routes
+}
+
+// settleLine menghitung Difference, Residue, dan Explained.
+func settleLine(l model.BalanceLine) model.BalanceLine {
+	l.Difference = l.SystemTotal - l.BankTotal
+	l.Residue = l.Difference - (l.MatchedDifference + l.UnmatchedSystem - l.UnmatchedBank)
+	l.Explained = l.Residue == 0
+	return l
+}
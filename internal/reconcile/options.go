@@ -0,0 +1,117 @@
+package reconcile
+
+import (
+	"fmt"
+	"time"
+
+	"amartha/internal/loader"
+)
+
+// StrategyAmount memasangkan record per tanggal dan tanda amount dengan
+// mengurutkan amount lalu two-pointer (strategi default).
+const StrategyAmount = "amount"
+
+// Options mengatur perilaku rekonsiliasi.
+type Options struct {
+	// Tolerance adalah selisih amount maksimum agar pasangan dianggap matched.
+	Tolerance int64
+	// BankTolerances menimpa Tolerance untuk bank tertentu (key: nama bank).
+	BankTolerances map[string]int64
+	// Strategy memilih algoritma matching; kosong berarti StrategyAmount.
+	Strategy string
+	// Location adalah zona waktu untuk menentukan tanggal transaksi sistem;
+	// nil berarti UTC.
+	Location *time.Location
+	// Balances adalah saldo awal/akhir statement bank; yang tanggalnya dalam
+	// rentang diverifikasi sebelum matching dan selisihnya dilaporkan sebagai
+	// BalanceBreak.
+	Balances []loader.StatementBalance
+	// BalanceReconciliation mengaktifkan bagian rekonsiliasi saldo (ledger vs
+	// bank) pada Result.
+	BalanceReconciliation bool
+	// DescriptionTieBreak memilih pasangan berdasarkan kemiripan narasi
+	// (ID/deskripsi sistem vs referensi/deskripsi bank) bila ada beberapa
+	// kandidat dengan amount sama pada tanggal yang sama.
+	DescriptionTieBreak bool
+	// FeeRules adalah biaya bank yang diketahui untuk mengklasifikasikan
+	// selisih pasangan matched (lihat FeeRule).
+	FeeRules []FeeRule
+	// Reversals mengaktifkan pre-pass pembatalan: pasangan record dalam satu
+	// sumber (sistem, atau satu bank) dengan amount sama dan tanda berlawanan
+	// dalam ReversalWindow hari dikeluarkan dari matching dan dilaporkan
+	// sebagai Reversal.
+	Reversals bool
+	// ReversalWindow adalah selisih hari maksimum antara record asli dan
+	// pembatalannya; 0 berarti hari yang sama.
+	ReversalWindow int
+	// ReversalAllowUnlinked juga menerima pembatalan yang tidak saling
+	// merujuk (secara default hanya pasangan dengan referensi sama, atau ID
+	// yang satu muncul di referensi/narasi yang lain), tetapi hanya bila kedua
+	// record tidak punya pasangan di sumber lain pada tanggal yang sama.
+	ReversalAllowUnlinked bool
+	// SignAgnostic memasangkan record yang tersisa setelah matching biasa
+	// berdasarkan amount absolut (tanda bank dibalik); pasangan tersebut
+	// ditandai SignMismatch.
+	SignAgnostic bool
+}
+
+// DefaultOptions mengembalikan opsi default: toleransi 5000, strategi amount, zona UTC.
+func DefaultOptions() Options {
+	return Options{
+		Tolerance: discrepancyTolerance,
+		Strategy:  StrategyAmount,
+		Location:  time.UTC,
+	}
+}
+
+// Validate memeriksa konsistensi opsi.
+func (o Options) Validate() error {
+	if o.Tolerance < 0 {
+		return fmt.Errorf("tolerance must not be negative: %d", o.Tolerance)
+	}
+	for bank, tol := range o.BankTolerances {
+		if tol < 0 {
+			return fmt.Errorf("tolerance for bank %q must not be negative: %d", bank, tol)
+		}
+	}
+	if o.ReversalWindow < 0 {
+		return fmt.Errorf("reversal window must not be negative: %d", o.ReversalWindow)
+	}
+	for i, r := range o.FeeRules {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("fee_rules[%d]: %w", i, err)
+		}
+	}
+	switch o.Strategy {
+	case "", StrategyAmount:
+	default:
+		return fmt.Errorf("unknown matching strategy %q", o.Strategy)
+	}
+	return nil
+}
+
+// toleranceFor mengembalikan toleransi selisih yang berlaku untuk bank tertentu.
+func (o Options) toleranceFor(bankName string) int64 {
+	if tol, ok := o.BankTolerances[bankName]; ok {
+		return tol
+	}
+	return o.Tolerance
+}
+
+// maxTolerance mengembalikan toleransi terbesar di antara semua bank.
+func (o Options) maxTolerance() int64 {
+	tol := o.Tolerance
+	for _, t := range o.BankTolerances {
+		if t > tol {
+			tol = t
+		}
+	}
+	return tol
+}
+
+func (o Options) location() *time.Location {
+	if o.Location == nil {
+		return time.UTC
+	}
+	return o.Location
+}
@@ -0,0 +1,169 @@
+package reconcile
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"amartha/internal/loader"
+	"amartha/internal/model"
+)
+
+// randomInput membangkitkan transaksi sistem dan bank acak dengan banyak
+// amount kembar dan hampir kembar, sebagian di luar rentang tanggal.
+func randomInput(r *rand.Rand) ([]model.SystemTransaction, map[string][]loader.BankStatement) {
+	base := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	amount := func() int64 {
+		v := int64(r.Intn(20)+1) * 25000
+		if r.Intn(3) == 0 {
+			v += int64(r.Intn(12000)) - 6000 // sekitar batas toleransi
+		}
+		return v
+	}
+	var sys []model.SystemTransaction
+	for i, n := 0, r.Intn(40); i < n; i++ {
+		typ := "CREDIT"
+		switch r.Intn(20) {
+		case 0:
+			typ = "DEBT" // salah ketik: harus dilaporkan, bukan dianggap credit
+		case 1, 2, 3, 4, 5, 6, 7, 8, 9:
+			typ = "DEBIT"
+		}
+		sys = append(sys, model.SystemTransaction{
+			TrxID:           fmt.Sprintf("TRX-%03d", i),
+			Amount:          amount(),
+			Type:            typ,
+			TransactionTime: base.AddDate(0, 0, r.Intn(7)-1).Add(time.Duration(r.Intn(24*60)) * time.Minute),
+		})
+	}
+	banks := map[string][]loader.BankStatement{}
+	for _, name := range []string{"bankA", "bankB", "bankC"} {
+		for i, n := 0, r.Intn(20); i < n; i++ {
+			amt := amount()
+			if r.Intn(2) == 0 {
+				amt = -amt
+			}
+			banks[name] = append(banks[name], loader.BankStatement{
+				UniqueIdentifier: fmt.Sprintf("%s-%03d", name, i),
+				Amount:           amt,
+				Date:             base.AddDate(0, 0, r.Intn(7)-1),
+				BankName:         name,
+			})
+		}
+	}
+	return sys, banks
+}
+
+func TestReconcile_Properties(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3)
+	for seed := int64(1); seed <= 300; seed++ {
+		r := rand.New(rand.NewSource(seed))
+		sys, banks := randomInput(r)
+		opts := DefaultOptions()
+		opts.Tolerance = int64(r.Intn(3)) * 2500
+		opts.BankTolerances = map[string]int64{"bankC": int64(r.Intn(3)) * 5000}
+		opts.DescriptionTieBreak = r.Intn(2) == 0
+		opts.Reversals = r.Intn(2) == 0
+		opts.ReversalWindow = r.Intn(3)
+		opts.ReversalAllowUnlinked = r.Intn(2) == 0
+		opts.SignAgnostic = r.Intn(2) == 0
+
+		res, err := ReconcileWithOptions(sys, banks, start, end, opts)
+		if err != nil {
+			t.Fatalf("seed %d: %v", seed, err)
+		}
+		checkInvariants(t, seed, sys, banks, start, end, opts, res)
+	}
+}
+
+func checkInvariants(t *testing.T, seed int64, sys []model.SystemTransaction, banks map[string][]loader.BankStatement,
+	start, end time.Time, opts Options, res model.Result) {
+	t.Helper()
+	inRange := func(d time.Time) bool {
+		d = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+		return !d.Before(start) && !d.After(end)
+	}
+
+	// Setiap record dalam rentang muncul tepat sekali di matched, unmatched,
+	// atau reversal.
+	seen := map[string]int{}
+	for _, m := range res.Details.Matched {
+		seen["sys:"+m.SystemID]++
+		seen[m.BankName+":"+m.BankID]++
+	}
+	for _, u := range res.Details.UnmatchedSystem {
+		seen["sys:"+u.ID]++
+	}
+	for _, u := range res.Details.UnknownTypes {
+		seen["sys:"+u.SystemID]++
+	}
+	for _, rv := range res.Details.Reversals {
+		src := rv.Source
+		if src == model.ReversalSourceSystem {
+			src = "sys"
+		}
+		seen[src+":"+rv.OriginalID]++
+		seen[src+":"+rv.ReversalID]++
+	}
+	for bank, recs := range res.Details.UnmatchedBankByGroup {
+		for _, u := range recs {
+			seen[bank+":"+u.ID]++
+		}
+	}
+	want := map[string]bool{}
+	for _, s := range sys {
+		if inRange(s.TransactionTime) {
+			want["sys:"+s.TrxID] = true
+		}
+	}
+	for bank, list := range banks {
+		for _, b := range list {
+			if inRange(b.Date) {
+				want[bank+":"+b.UniqueIdentifier] = true
+			}
+		}
+	}
+	for k := range want {
+		if seen[k] != 1 {
+			t.Fatalf("seed %d: record %s appears %d times", seed, k, seen[k])
+		}
+	}
+	for k := range seen {
+		if !want[k] {
+			t.Fatalf("seed %d: out-of-range or unknown record %s in result", seed, k)
+		}
+	}
+
+	// TotalProcessed = matched*2 + unmatched + reversal*2 + unknown type.
+	s := res.Summary
+	if s.TotalProcessed != s.TotalMatched*2+s.TotalUnmatched+s.TotalReversals*2+s.TotalUnknownTypes || s.TotalProcessed != len(want) {
+		t.Fatalf("seed %d: inconsistent summary %+v (records %d)", seed, s, len(want))
+	}
+
+	// Tidak ada pasangan yang melebihi toleransi; pasangan bertanda sama
+	// kecuali pasangan lintas tanda pada mode sign-agnostic.
+	var total int64
+	mismatches := 0
+	for _, m := range res.Details.Matched {
+		bankAmount := m.BankAmount
+		if m.SignMismatch {
+			bankAmount = -bankAmount
+			mismatches++
+		}
+		if m.Discrepancy != abs64(m.SystemAmount-bankAmount) || m.Discrepancy > opts.toleranceFor(m.BankName) {
+			t.Fatalf("seed %d: pair exceeds tolerance: %+v", seed, m)
+		}
+		if ((m.SystemAmount < 0) != (m.BankAmount < 0)) != m.SignMismatch || (m.SignMismatch && !opts.SignAgnostic) {
+			t.Fatalf("seed %d: pair sign does not match its SignMismatch flag: %+v", seed, m)
+		}
+		total += m.Discrepancy
+	}
+	if mismatches != s.TotalSignMismatches {
+		t.Fatalf("seed %d: total sign mismatches %d, want %d", seed, s.TotalSignMismatches, mismatches)
+	}
+	if total != s.TotalDiscrepancies {
+		t.Fatalf("seed %d: total discrepancies %d, want %d", seed, s.TotalDiscrepancies, total)
+	}
+}
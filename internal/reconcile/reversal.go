@@ -0,0 +1,129 @@
+package reconcile
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"amartha/internal/model"
+)
+
+// reversalItem adalah record kandidat pembatalan dalam satu sumber.
+type reversalItem struct {
+	model.NormalizedRecord
+	Reference string
+}
+
+// linkedTo melaporkan apakah dua record saling merujuk: referensinya sama,
+// atau ID yang satu muncul di referensi/narasi yang lain.
+func (a reversalItem) linkedTo(b reversalItem) bool {
+	if a.Reference != "" && strings.EqualFold(a.Reference, b.Reference) {
+		return true
+	}
+	return mentions(a, b.ID) || mentions(b, a.ID)
+}
+
+func mentions(r reversalItem, id string) bool {
+	if len(id) < 3 {
+		return false
+	}
+	id = strings.ToLower(id)
+	return strings.Contains(strings.ToLower(r.Reference), id) || strings.Contains(strings.ToLower(r.Description), id)
+}
+
+// counterpartIndex adalah record sumber lain (bank untuk sistem, sistem untuk
+// bank) per tanggal, untuk memeriksa apakah sebuah record masih punya calon
+// pasangan matching.
+type counterpartIndex map[time.Time][]counterpart
+
+type counterpart struct {
+	amount    int64
+	tolerance int64
+}
+
+func (ix counterpartIndex) add(date time.Time, amount, tolerance int64) {
+	ix[date] = append(ix[date], counterpart{amount: amount, tolerance: tolerance})
+}
+
+// has melaporkan apakah ada record bertanda sama pada tanggal r yang
+// selisihnya dalam toleransi.
+func (ix counterpartIndex) has(r model.NormalizedRecord) bool {
+	for _, c := range ix[r.Date] {
+		if (c.amount >= 0) == (r.Amount >= 0) && abs64(c.amount-r.Amount) <= c.tolerance {
+			return true
+		}
+	}
+	return false
+}
+
+// findReversals mencari pasangan record dalam satu sumber dengan amount sama
+// dan tanda berlawanan yang tanggalnya berselisih paling banyak
+// opts.ReversalWindow hari. Record diproses kronologis (lalu per ID); untuk
+// setiap record dipilih pembatalan yang saling merujuk lebih dulu, lalu yang
+// tanggalnya terdekat. Pasangan yang tidak saling merujuk hanya diterima
+// dengan opts.ReversalAllowUnlinked dan bila kedua record tidak punya pasangan
+// di others, karena pencairan dan angsuran bernominal sama lazim terjadi.
+// Record yang dipasangkan ditandai di reversed.
+func findReversals(source string, items []reversalItem, others counterpartIndex, opts Options) (reversed []bool, out []model.Reversal) {
+	reversed = make([]bool, len(items))
+	paired := make([]bool, len(items))
+	if opts.ReversalAllowUnlinked {
+		for i, it := range items {
+			paired[i] = others.has(it.NormalizedRecord)
+		}
+	}
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(x, y int) bool {
+		a, b := items[order[x]], items[order[y]]
+		if !a.Date.Equal(b.Date) {
+			return a.Date.Before(b.Date)
+		}
+		return a.ID < b.ID
+	})
+	byAmount := map[int64][]int{}
+	for _, i := range order {
+		byAmount[abs64(items[i].Amount)] = append(byAmount[abs64(items[i].Amount)], i)
+	}
+
+	for _, i := range order {
+		a := items[i]
+		if reversed[i] || a.Amount == 0 {
+			continue
+		}
+		best, bestLinked, bestGap := -1, false, 0
+		for _, j := range byAmount[abs64(a.Amount)] {
+			b := items[j]
+			if j == i || reversed[j] || b.Amount != -a.Amount {
+				continue
+			}
+			gap := int(b.Date.Sub(a.Date).Hours() / 24)
+			if gap < 0 || gap > opts.ReversalWindow {
+				continue
+			}
+			linked := a.linkedTo(b)
+			if !linked && (!opts.ReversalAllowUnlinked || paired[i] || paired[j]) {
+				continue
+			}
+			if best < 0 || (linked && !bestLinked) || (linked == bestLinked && gap < bestGap) {
+				best, bestLinked, bestGap = j, linked, gap
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		reversed[i], reversed[best] = true, true
+		out = append(out, model.Reversal{
+			Source:       source,
+			OriginalID:   a.ID,
+			ReversalID:   items[best].ID,
+			OriginalDate: a.Date.Format("2006-01-02"),
+			ReversalDate: items[best].Date.Format("2006-01-02"),
+			Amount:       a.Amount,
+			Linked:       bestLinked,
+		})
+	}
+	return reversed, out
+}
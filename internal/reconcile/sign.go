@@ -0,0 +1,110 @@
+package reconcile
+
+import (
+	"sort"
+	"time"
+
+	"amartha/internal/model"
+)
+
+const (
+	// minSignSample adalah jumlah minimum record bank yang memiliki pasangan
+	// amount persis di sistem sebelum inversi tanda dilaporkan.
+	minSignSample = 5
+	// signInversionRatio adalah porsi minimum record yang hanya cocok dengan
+	// tanda berlawanan agar bank dianggap terbalik tandanya.
+	signInversionRatio = 0.8
+)
+
+// detectSignInversions mencari file bank yang tanda amount-nya tampak
+// terbalik secara sistematis: di antara record file itu yang memiliki
+// transaksi sistem dengan amount absolut sama pada tanggal yang sama,
+// sebagian besar hanya cocok dengan tanda berlawanan. Dihitung per file agar
+// satu file terbalik tidak tertutup file lain dengan nama bank yang sama.
+func detectSignInversions(sys []model.NormalizedRecord, bank []bankRec) []model.SignInversion {
+	type dateAmount struct {
+		date   time.Time
+		amount int64
+	}
+	sysAmounts := map[dateAmount]bool{}
+	for _, s := range sys {
+		sysAmounts[dateAmount{s.Date, s.Amount}] = true
+	}
+
+	type file struct{ bank, source string }
+	type stat struct{ compared, inverted int }
+	stats := map[file]*stat{}
+	for _, b := range bank {
+		if b.Amount == 0 {
+			continue
+		}
+		same := sysAmounts[dateAmount{b.Date, b.Amount}]
+		opposite := sysAmounts[dateAmount{b.Date, -b.Amount}]
+		if !same && !opposite {
+			continue
+		}
+		key := file{b.BankName, b.Source}
+		st := stats[key]
+		if st == nil {
+			st = &stat{}
+			stats[key] = st
+		}
+		st.compared++
+		if !same {
+			st.inverted++
+		}
+	}
+
+	out := []model.SignInversion{}
+	for f, st := range stats {
+		ratio := float64(st.inverted) / float64(st.compared)
+		if st.compared >= minSignSample && ratio >= signInversionRatio {
+			out = append(out, model.SignInversion{BankName: f.bank, Source: f.source, Compared: st.compared, Inverted: st.inverted, Ratio: ratio})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].BankName != out[j].BankName {
+			return out[i].BankName < out[j].BankName
+		}
+		return out[i].Source < out[j].Source
+	})
+	return out
+}
+
+// matchFlipped memasangkan record sistem dengan record bank yang tanda
+// amount-nya dibalik. Pasangan ditandai SignMismatch dan tetap memuat amount
+// bank asli; record bank yang tersisa dikembalikan dengan tanda asli.
+func matchFlipped(sys []model.NormalizedRecord, bank []bankRec, opts Options) ([]model.MatchedPair, []model.NormalizedRecord, []bankRec) {
+	flipped := make([]bankRec, len(bank))
+	for i, b := range bank {
+		b.Amount = -b.Amount
+		flipped[i] = b
+	}
+	matched, umSys, umBank, _ := matchByDateAndAmount(sys, flipped, opts)
+	for i := range matched {
+		matched[i].BankAmount = -matched[i].BankAmount
+		matched[i].SignMismatch = true
+	}
+	for i := range umBank {
+		umBank[i].Amount = -umBank[i].Amount
+	}
+	return matched, umSys, umBank
+}
+
+// dropMatchedMisrouted membuang laporan misrouted yang record sistem atau
+// statement bank-nya akhirnya matched.
+func dropMatchedMisrouted(misrouted []model.MisroutedMatch, matched []model.MatchedPair) []model.MisroutedMatch {
+	sysIDs := map[string]bool{}
+	bankIDs := map[[2]string]bool{}
+	for _, m := range matched {
+		sysIDs[m.SystemID] = true
+		bankIDs[[2]string{m.BankName, m.BankID}] = true
+	}
+	out := misrouted[:0:0]
+	for _, m := range misrouted {
+		if !sysIDs[m.SystemID] && !bankIDs[[2]string{m.BankName, m.BankID}] {
+			out = append(out, m)
+		}
+	}
+	return out
+}
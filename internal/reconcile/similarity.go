@@ -0,0 +1,76 @@
+package reconcile
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalizeText mengubah teks menjadi token huruf kecil alfanumerik,
+// mis. "Angsuran L-0042, Siti" menjadi [angsuran l 0042 siti].
+func normalizeText(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// textSimilarity mengembalikan skor 0..1 antara dua teks: nilai terbesar dari
+// token overlap (Jaccard, token dianggap sama bila jarak Levenshtein-nya kecil)
+// dan rasio Levenshtein atas teks yang dinormalisasi.
+func textSimilarity(a, b string) float64 {
+	ta, tb := normalizeText(a), normalizeText(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		return 0
+	}
+	return max(tokenOverlap(ta, tb), levenshteinRatio(strings.Join(ta, " "), strings.Join(tb, " ")))
+}
+
+// tokenOverlap menghitung |A ∩ B| / |A ∪ B| dengan toleransi salah ketik satu
+// karakter untuk token sepanjang 5 karakter atau lebih.
+func tokenOverlap(a, b []string) float64 {
+	used := make([]bool, len(b))
+	common := 0
+	for _, x := range a {
+		for i, y := range b {
+			if used[i] {
+				continue
+			}
+			if x == y || (len(x) >= 5 && len(y) >= 5 && levenshtein(x, y) <= 1) {
+				used[i] = true
+				common++
+				break
+			}
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+func levenshteinRatio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	n := max(len(ra), len(rb))
+	if n == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(a, b))/float64(n)
+}
+
+// levenshtein menghitung jarak edit (insert, delete, substitute) antar rune.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(rb)]
+}
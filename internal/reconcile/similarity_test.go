@@ -0,0 +1,22 @@
+package reconcile
+
+import "testing"
+
+func TestTextSimilarity(t *testing.T) {
+	if got := textSimilarity("Angsuran L-0042 Siti Aminah", "ANGSURAN PINJAMAN L 0042 / SITI AMINAH"); got < 0.7 {
+		t.Fatalf("expected high similarity, got %.2f", got)
+	}
+	// Salah ketik satu huruf pada nama tetap dianggap token yang sama.
+	if got := textSimilarity("Budi Santoso", "budi santosa"); got != 1 {
+		t.Fatalf("expected typo-tolerant match, got %.2f", got)
+	}
+	if got := textSimilarity("Angsuran L-0042", "Biaya administrasi"); got > 0.3 {
+		t.Fatalf("expected low similarity, got %.2f", got)
+	}
+	if got := textSimilarity("", "anything"); got != 0 {
+		t.Fatalf("expected 0 for empty text, got %.2f", got)
+	}
+	if d := levenshtein("kitten", "sitting"); d != 3 {
+		t.Fatalf("levenshtein(kitten, sitting)=%d", d)
+	}
+}